@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/Cludch/csgo-tools/internal/app"
+	log "github.com/sirupsen/logrus"
+)
+
+const usage = `Usage: csgo-tools <command>
+
+Commands:
+  serve-api    Serves the REST API
+  auth         Serves the Steam sign in
+  poll-valve   Checks Valve's game history API for new share codes
+  poll-faceit  Checks Faceit's API for new matches
+  gc           Requests download urls from the GameCoordinator
+  download     Downloads demos
+  parse        Parses demos and deletes them according to the retention rules
+  all          Runs all of the above in one process
+`
+
+// Time the database connection has to close when shutting down.
+const closeTimeout = 10 * time.Second
+
+func main() {
+	if len(os.Args) != 2 || !isCommand(os.Args[1]) {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	command := os.Args[1]
+
+	log.SetFormatter(&log.TextFormatter{
+		FullTimestamp: true,
+		DisableColors: false,
+	})
+
+	// Cancelling the context stops all components. Running jobs are released so they can be resumed.
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	a, err := app.New(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := a.Start(ctx, command); err != nil {
+		log.Fatal(err)
+	}
+
+	log.Info("shutting down")
+
+	closeCtx, cancel := context.WithTimeout(context.Background(), closeTimeout)
+	defer cancel()
+
+	if err := a.Close(closeCtx); err != nil {
+		log.Error(err)
+	}
+}
+
+// Returns whether the argument names one of the commands.
+func isCommand(arg string) bool {
+	for _, command := range app.Commands() {
+		if arg == command {
+			return true
+		}
+	}
+
+	return false
+}
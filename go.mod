@@ -11,16 +11,19 @@ require (
 	github.com/go-playground/validator/v10 v10.9.0 // indirect
 	github.com/go-stack/stack v1.8.1 // indirect
 	github.com/golang-jwt/jwt v3.2.1+incompatible
+	github.com/golang/geo v0.0.0-20210211234256-740aa86cb551
 	github.com/golang/protobuf v1.4.3
 	github.com/golang/snappy v0.0.4 // indirect
 	github.com/google/go-cmp v0.5.6 // indirect
 	github.com/google/uuid v1.3.0
 	github.com/gorilla/sessions v1.1.1
 	github.com/json-iterator/go v1.1.12 // indirect
+	github.com/klauspost/compress v1.13.6
 	github.com/magiconair/properties v1.8.5 // indirect
 	github.com/markbates/goth v1.68.0
 	github.com/markus-wa/demoinfocs-golang/v2 v2.12.0
 	github.com/mattn/go-isatty v0.0.14 // indirect
+	github.com/minio/minio-go/v7 v7.0.16
 	github.com/mitchellh/mapstructure v1.4.2 // indirect
 	github.com/pelletier/go-toml v1.9.4 // indirect
 	github.com/sirupsen/logrus v1.8.1
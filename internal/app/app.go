@@ -0,0 +1,86 @@
+package app
+
+import (
+	"context"
+
+	"github.com/Cludch/csgo-tools/internal/auth"
+	"github.com/Cludch/csgo-tools/internal/config"
+	"github.com/Cludch/csgo-tools/internal/demostore"
+	"github.com/Cludch/csgo-tools/internal/discord_client"
+	"github.com/Cludch/csgo-tools/internal/domain/entity"
+	"github.com/Cludch/csgo-tools/internal/domain/heatmap"
+	"github.com/Cludch/csgo-tools/internal/domain/job"
+	"github.com/Cludch/csgo-tools/internal/domain/match"
+	"github.com/Cludch/csgo-tools/internal/domain/player"
+	"github.com/Cludch/csgo-tools/internal/domain/user"
+	"github.com/Cludch/csgo-tools/internal/gamecoordinator"
+	"github.com/Cludch/csgo-tools/internal/steam_client"
+	"github.com/Cludch/csgo-tools/pkg/demo"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+// App holds the services shared by all components of the application.
+type App struct {
+	ConfigService          *config.Service
+	MatchService           *match.Service
+	PlayerService          *player.Service
+	HeatmapService         *heatmap.Service
+	UserService            *user.Service
+	JobService             *job.Service
+	AuthService            *auth.Service
+	GamecoordinatorService *gamecoordinator.Service
+	SteamService           *steam_client.Service
+	DiscordService         *discord_client.Service
+	DemoStore              demo.DemoStore
+
+	db *entity.Service
+
+	// Router is shared by the components serving http and created by the first one of them.
+	router *gin.Engine
+}
+
+// New reads the configuration, connects to the database and creates all services.
+func New(ctx context.Context) (*App, error) {
+	configService, err := config.NewService()
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := entity.NewService(ctx, configService)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &App{ConfigService: configService, db: db}
+	a.MatchService = match.NewService(match.NewRepositoryMongo(db), configService)
+	a.PlayerService = player.NewService(player.NewRepositoryMongo(db))
+	a.HeatmapService = heatmap.NewService(heatmap.NewRepositoryMongo(db))
+	a.UserService = user.NewService(user.NewRepositoryMongo(db), configService)
+	a.JobService = job.NewService(job.NewRepositoryMongo(db))
+	a.AuthService = auth.NewService(configService, a.UserService)
+	a.GamecoordinatorService = gamecoordinator.NewService(configService, a.MatchService, a.JobService)
+	a.SteamService = steam_client.NewService(a.GamecoordinatorService)
+
+	if a.DemoStore, err = demostore.New(configService.GetConfig()); err != nil {
+		return nil, err
+	}
+
+	if configService.GetConfig().Discord.Enabled {
+		log.Info("discord bot enabled")
+		a.DiscordService = discord_client.NewService(configService.GetConfig().Discord.DiscordAPIKey)
+	} else {
+		log.Info("discord bot disabled")
+	}
+
+	if !configService.IsDebug() {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	return a, nil
+}
+
+// Close closes the connections to the database.
+func (a *App) Close(ctx context.Context) error {
+	return a.db.Disconnect(ctx)
+}
@@ -0,0 +1,107 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+// Component registers the tasks of one part of the application with the scheduler.
+type Component func(a *App, s *Scheduler)
+
+// All is the name of the command running every component in one process.
+const All = "all"
+
+// Components maps the command names to the components of the application.
+var Components = map[string]Component{
+	"serve-api":   ServeAPI,
+	"auth":        Auth,
+	"poll-valve":  PollValve,
+	"poll-faceit": PollFaceit,
+	"gc":          GameClient,
+	"download":    Download,
+	"parse":       Parse,
+}
+
+// Time the running requests have to finish when shutting down.
+const shutdownTimeout = 10 * time.Second
+
+// Order in which the components are started by the all command.
+var order = []string{"serve-api", "auth", "poll-valve", "poll-faceit", "gc", "download", "parse"}
+
+// Commands returns the names of all commands including the all command.
+func Commands() []string {
+	commands := make([]string, 0, len(order)+1)
+	commands = append(commands, order...)
+
+	return append(commands, All)
+}
+
+// Start registers the components of the command with one shared scheduler and runs them until the context is
+// cancelled.
+func (a *App) Start(ctx context.Context, command string) error {
+	names := []string{command}
+	if command == All {
+		names = order
+	}
+
+	s := NewScheduler()
+	for _, name := range names {
+		component, ok := Components[name]
+		if !ok {
+			return fmt.Errorf("app: unknown command %s", name)
+		}
+
+		const msg = "starting %s"
+		log.Infof(msg, name)
+
+		s.component = name
+		component(a, s)
+	}
+
+	s.Run(ctx)
+	return nil
+}
+
+// Returns the router shared by all components serving http. The first call schedules serving it.
+func (a *App) httpRouter(s *Scheduler) *gin.Engine {
+	if a.router != nil {
+		return a.router
+	}
+
+	a.router = gin.Default()
+	s.Go("http", a.serve)
+
+	return a.router
+}
+
+// Serves the router until the context is cancelled and waits for the running requests to finish.
+// By default it serves on :8080 unless a PORT environment variable was defined.
+func (a *App) serve(ctx context.Context) error {
+	addr := ":8080"
+	if port := os.Getenv("PORT"); port != "" {
+		addr = ":" + port
+	}
+
+	server := &http.Server{Addr: addr, Handler: a.router}
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		return server.Shutdown(shutdownCtx)
+	}
+}
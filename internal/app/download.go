@@ -0,0 +1,154 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Cludch/csgo-tools/internal/config"
+	"github.com/Cludch/csgo-tools/internal/domain/entity"
+	"github.com/Cludch/csgo-tools/internal/domain/job"
+	"github.com/Cludch/csgo-tools/internal/domain/match"
+	"github.com/Cludch/csgo-tools/pkg/util"
+	log "github.com/sirupsen/logrus"
+)
+
+// Downloads the demos of download jobs.
+type downloader struct {
+	*App
+	config  *config.DownloadConfig
+	limiter *util.HostLimiter
+}
+
+// Download downloads the demos of downloadable matches using parallel workers.
+func Download(a *App, s *Scheduler) {
+	d := &downloader{App: a, config: a.ConfigService.GetDownloadConfig()}
+	d.limiter = util.NewHostLimiter(map[string]time.Duration{
+		"valve.net":      time.Duration(d.config.ValveRequestInterval) * time.Millisecond,
+		"faceit-cdn.net": time.Duration(d.config.FaceitRequestInterval) * time.Millisecond,
+	}, 0)
+
+	s.Go("download-enqueue", d.enqueueDownloadable)
+
+	msg := "using %d download workers"
+	log.Infof(msg, d.config.WorkerCount)
+
+	// Start parallel workers.
+	for w := 1; w <= d.config.WorkerCount; w++ {
+		// Every worker needs its own owner, otherwise a stalled worker would not notice that another worker of this
+		// process took over its job.
+		owner := job.NewOwner(fmt.Sprintf("download-%d", w))
+		s.Go("download", func(ctx context.Context) error {
+			a.JobService.Run(ctx, job.Download, owner, d.download, d.backoff)
+			return nil
+		})
+	}
+}
+
+// Enqueues matches that became downloadable before the job queue existed or whose jobs got lost.
+func (d *downloader) enqueueDownloadable(ctx context.Context) error {
+	nonDownloadedMatches, err := d.MatchService.GetDownloadableMatches(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range nonDownloadedMatches {
+		if err := d.JobService.Enqueue(ctx, job.Download, m.ID, d.config.MaxAttempts); err != nil {
+			log.Error(err)
+		}
+	}
+
+	return nil
+}
+
+// Returns the delay before retrying a failed download.
+func (d *downloader) backoff(attempts int) time.Duration {
+	return match.DownloadBackoff(attempts, time.Duration(d.config.Backoff)*time.Second)
+}
+
+// Downloads the demo of the match of the job and records the result.
+func (d *downloader) download(ctx context.Context, j *job.Job) error {
+	m, err := d.MatchService.GetMatch(ctx, j.MatchID)
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			return job.Permanent(err)
+		}
+		return err
+	}
+
+	// The match may have been downloaded or merged into another match in the meantime.
+	if m.Status != match.Downloadable {
+		return nil
+	}
+
+	// Valve deletes matchmaking demos after a while.
+	if expired, err := d.MatchService.ExpireMatch(ctx, m); expired || err != nil {
+		return err
+	}
+
+	url := m.DownloadURL
+	if err := d.limiter.Wait(ctx, url); err != nil {
+		// The job is released if the worker is shutting down.
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return job.Permanent(err)
+	}
+
+	result, err := util.DownloadDemo(ctx, url, d.DemoStore, d.downloadDir(), m.Time, d.compress())
+	if err != nil {
+		// Downloads interrupted by a shutdown are resumed once the released job gets claimed again.
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		log.Error(err)
+
+		if util.IsDemoNotFoundError(err) {
+			if err := d.MatchService.UpdateStatus(ctx, m, match.Unavailable, err.Error()); err != nil {
+				log.Error(err)
+			}
+			return job.Permanent(err)
+		}
+
+		// Failed downloads get resumed by the next attempt of the job. The match changes to Error once it has none left.
+		if err := d.MatchService.FailDownload(ctx, m, j.Attempts, j.MaxAttempts, err); err != nil {
+			log.Error(err)
+		}
+
+		if !j.HasAttemptsLeft() {
+			const msg = "giving up downloading %s after %d attempts"
+			log.Warnf(msg, url, j.Attempts)
+			return job.Permanent(err)
+		}
+		return err
+	}
+
+	const msg = "downloaded demo %s (%d bytes in %s)"
+	log.Infof(msg, result.Filename, result.Size, result.Duration)
+
+	// Mark as downloaded and save file name.
+	info := &match.DownloadInfo{Size: result.Size, Duration: result.Duration, Checksum: result.Fingerprint.Checksum, Time: time.Now()}
+	if err := d.MatchService.CompleteDownload(ctx, m, result.Filename, info, result.Fingerprint); err != nil {
+		return err
+	}
+
+	return d.JobService.Enqueue(ctx, job.Parse, m.ID, job.DefaultMaxAttempts)
+}
+
+// Returns the directory holding partial downloads. Defaults to the demos dir.
+func (d *downloader) downloadDir() string {
+	c := d.ConfigService.GetConfig()
+	if c.Storage != nil && c.Storage.DownloadDir != "" {
+		return c.Storage.DownloadDir
+	}
+
+	return c.DemosDir
+}
+
+// Returns whether downloaded demos should be compressed.
+func (d *downloader) compress() bool {
+	storageConfig := d.ConfigService.GetConfig().Storage
+	return storageConfig != nil && storageConfig.Compress
+}
@@ -0,0 +1,21 @@
+package app
+
+import (
+	"context"
+
+	"github.com/Philipp15b/go-steam/v2"
+	log "github.com/sirupsen/logrus"
+)
+
+// GameClient signs in to Steam and requests the download urls of matchmaking matches from the GameCoordinator.
+// The client reconnects if the connection gets lost.
+func GameClient(a *App, s *Scheduler) {
+	if err := steam.InitializeSteamDirectory(); err != nil {
+		log.Error(err)
+	}
+
+	steamConfig := a.ConfigService.GetConfig().Steam
+	s.Go("gc", func(ctx context.Context) error {
+		return a.SteamService.Connect(ctx, steamConfig.Username, steamConfig.Password, steamConfig.TwoFactorSecret)
+	})
+}
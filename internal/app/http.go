@@ -0,0 +1,60 @@
+package app
+
+import (
+	"github.com/Cludch/csgo-tools/internal/auth"
+	"github.com/Cludch/csgo-tools/internal/domain/heatmap"
+	"github.com/Cludch/csgo-tools/internal/domain/match"
+	"github.com/Cludch/csgo-tools/internal/domain/player"
+	"github.com/gorilla/sessions"
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/gothic"
+	"github.com/markbates/goth/providers/steam"
+)
+
+// ServeAPI serves the REST API.
+func ServeAPI(a *App, s *Scheduler) {
+	router := a.httpRouter(s)
+
+	matchController := match.NewController(a.MatchService, a.DemoStore)
+	playerController := player.NewController(a.PlayerService)
+	heatmapController := heatmap.NewController(a.HeatmapService, a.ConfigService.GetConfig().RadarDir)
+
+	router.GET("/match", matchController.GetMatches)
+	router.GET("/match/:id", matchController.GetMatchDetails)
+	router.GET("/match/:id/damage", matchController.GetMatchDamage)
+	router.GET("/match/:id/openings", matchController.GetMatchOpeningDuels)
+	router.GET("/match/:id/heatmap", heatmapController.GetMatchHeatmap)
+	router.GET("/match/:id/rounds/:n", matchController.GetRoundTimeline)
+	router.GET("/match/:id/rounds/:n/replay", matchController.GetRoundReplay)
+	router.GET("/map/:map/sites", matchController.GetSiteStats)
+	router.GET("/player/", playerController.GetPlayers)
+	router.GET("/player/:id", playerController.GetPlayerDetails)
+	router.GET("/player/:id/stats", playerController.GetPlayerAverageStats)
+	router.GET("/player/:id/weapons", playerController.GetPlayerWeaponStats)
+	router.GET("/player/:id/heatmap/:map", heatmapController.GetPlayerHeatmap)
+}
+
+// Auth serves the Steam sign in and the details of the signed in user.
+func Auth(a *App, s *Scheduler) {
+	router := a.httpRouter(s)
+	authController := auth.NewController(a.AuthService, a.UserService)
+
+	// this store is not to be used. We use JWT tokens.
+	gothic.Store = sessions.NewCookieStore([]byte(""))
+
+	// Register Steam as Goth OpenID 2.0 provider
+	c := a.ConfigService.GetConfig()
+	goth.UseProviders(
+		steam.New(c.Steam.SteamAPIKey, c.Auth.Host+"/auth/steam/callback"),
+	)
+
+	router.GET("/auth/:provider", authController.Auth)
+	router.GET("/auth/:provider/callback", authController.Callback)
+
+	// Protected API endpoints.
+	authorized := router.Group("/")
+	authorized.Use(authController.AuthorizeRequest)
+	{
+		authorized.GET("/me", authController.GetUserDetails)
+	}
+}
@@ -0,0 +1,400 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Cludch/csgo-tools/internal/config"
+	"github.com/Cludch/csgo-tools/internal/demoparser"
+	"github.com/Cludch/csgo-tools/internal/domain/entity"
+	"github.com/Cludch/csgo-tools/internal/domain/heatmap"
+	"github.com/Cludch/csgo-tools/internal/domain/job"
+	"github.com/Cludch/csgo-tools/internal/domain/match"
+	"github.com/Cludch/csgo-tools/pkg/demo"
+	log "github.com/sirupsen/logrus"
+)
+
+// ParserVersion is stored with each parsed match. Matches parsed with an older version get reparsed.
+const ParserVersion = 29
+
+// Parse parses the downloaded and manually uploaded demos using parallel workers and deletes demos according to the
+// retention rules each minute.
+func Parse(a *App, s *Scheduler) {
+	s.Go("parse-enqueue", a.enqueueParseable)
+
+	numJobs, _ := strconv.ParseInt(a.ConfigService.GetConfig().Parser.WorkerCount, 10, 32)
+
+	msg := "using %d parse workers"
+	log.Infof(msg, numJobs)
+
+	// Start numJobs-times parallel workers.
+	for w := int64(1); w <= numJobs; w++ {
+		owner := job.NewOwner(fmt.Sprintf("parse-%d", w))
+		s.Go("parse", func(ctx context.Context) error {
+			a.JobService.Run(ctx, job.Parse, owner, a.parse, parseBackoff)
+			return nil
+		})
+	}
+
+	s.Every("retention", time.Minute, a.enforceRetention)
+}
+
+// Scans the store for new demos and enqueues the matches that have not been parsed with the current parser version.
+func (a *App) enqueueParseable(ctx context.Context) error {
+	a.scanDemos(ctx)
+
+	nonParsedMatches, err := a.MatchService.GetParseableMatches(ctx, ParserVersion)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range nonParsedMatches {
+		if err := a.JobService.Enqueue(ctx, job.Parse, m.ID, job.DefaultMaxAttempts); err != nil {
+			log.Error(err)
+		}
+	}
+
+	return nil
+}
+
+// Returns the delay before retrying a failed parse job.
+func parseBackoff(attempts int) time.Duration {
+	return time.Duration(attempts) * time.Minute
+}
+
+// Scans the store for new demo files and creates manual uploads for them. Files containing the demo of an existing
+// match are added to that match and reported as duplicates.
+func (a *App) scanDemos(ctx context.Context) {
+	demos, err := demo.Scan(ctx, a.DemoStore)
+	if err != nil {
+		log.Error(err)
+	}
+
+	duplicates := 0
+	for _, demoFile := range demos {
+		if m, err := a.MatchService.GetMatchByFilename(ctx, demoFile.Filename); err == nil {
+			if m.Filename != demoFile.Filename {
+				duplicates++
+			}
+			continue
+		}
+
+		fingerprint, err := demo.FingerprintOf(ctx, a.DemoStore, demoFile.Filename)
+		if err != nil {
+			const msg = "unable to create the fingerprint of demo file %s: %s"
+			log.Warnf(msg, demoFile.Filename, err)
+		}
+
+		m, err := a.MatchService.CreateMatchFromManualUpload(ctx, demoFile.Filename, demoFile.MatchTime, fingerprint)
+		if errors.Is(err, match.ErrDuplicateDemo) {
+			duplicates++
+			const msg = "demo file %s is a duplicate of the demo %s of match %s"
+			log.Warnf(msg, demoFile.Filename, m.Filename, m.ID)
+		} else if err != nil {
+			msg := "unable to create manual uploaded demo for file %s"
+			log.Warn(msg, demoFile.Filename)
+		} else if m != nil {
+			msg := "found demo file %s and created manual upload entity"
+			log.Infof(msg, m.Filename)
+
+			if err := a.JobService.Enqueue(ctx, job.Parse, m.ID, job.DefaultMaxAttempts); err != nil {
+				log.Error(err)
+			}
+		}
+	}
+
+	if duplicates > 0 {
+		const msg = "found %d duplicate demo files"
+		log.Warnf(msg, duplicates)
+	}
+}
+
+// Merges the match with all matches of the same demo. The results of merged matches are deleted as the demo gets
+// parsed for the remaining match. Returns whether the match itself remains and should be parsed.
+func (a *App) mergeDuplicates(ctx context.Context, m *match.Match) bool {
+	if m.Fingerprint == nil {
+		fingerprint, err := demo.FingerprintOf(ctx, a.DemoStore, m.Filename)
+		if err != nil {
+			const msg = "unable to create the fingerprint of demo file %s: %s"
+			log.Warnf(msg, m.Filename, err)
+			return true
+		}
+
+		if err := a.MatchService.SetFingerprint(ctx, m, fingerprint); err != nil {
+			log.Error(err)
+			return true
+		}
+	}
+
+	target, merged, err := a.MatchService.MergeDuplicates(ctx, m)
+	if err != nil {
+		log.Error(err)
+	}
+
+	for _, duplicate := range merged {
+		if err := a.PlayerService.DeleteMatchResults(ctx, duplicate.ID); err != nil {
+			log.Error(err)
+		}
+
+		if err := a.HeatmapService.DeleteHeatmap(ctx, duplicate.ID); err != nil && !errors.Is(err, entity.ErrNotFound) {
+			log.Error(err)
+		}
+	}
+
+	// The match may have taken over the demo of the duplicate.
+	if target.ID != m.ID && target.Status == match.Downloaded {
+		if err := a.JobService.Enqueue(ctx, job.Parse, target.ID, job.DefaultMaxAttempts); err != nil {
+			log.Error(err)
+		}
+	}
+
+	return target.ID == m.ID
+}
+
+// Parses and persists the match of the job.
+func (a *App) parse(ctx context.Context, j *job.Job) error {
+	m, err := a.MatchService.GetMatch(ctx, j.MatchID)
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			return job.Permanent(err)
+		}
+		return err
+	}
+
+	filename := m.Filename
+	if filename == "" {
+		return job.Permanent(errors.New("demoparser: match has no demo file"))
+	}
+
+	// The match may have been parsed by a previous attempt.
+	if m.Status == match.Parsed && m.Result.ParserVersion >= ParserVersion {
+		return nil
+	}
+
+	parser := demoparser.NewService(a.ConfigService)
+	demoFile := &demo.Demo{ID: m.ID, MatchTime: m.CreatedAt, Filename: filename}
+
+	// Check if file exists. File may have gotten deleted after being parsed the first time.
+	if !demo.Exists(ctx, a.DemoStore, demoFile.Filename) {
+		log.Warnf("Demo file %v for match with id %v is no longer available.", demoFile.Filename, demoFile.ID)
+
+		// Parsed matches keep their result, others can not be parsed anymore.
+		if m.Status != match.Parsed {
+			if err := a.MatchService.UpdateStatus(ctx, m, match.Unavailable, "demo file is no longer available"); err != nil {
+				log.Error(err)
+			}
+		}
+
+		if err := a.MatchService.SetFileState(ctx, m, match.FileDeleted, demoFile.Filename); err != nil {
+			log.Error(err)
+		}
+
+		return job.Permanent(fmt.Errorf("demoparser: demo file %s is no longer available", demoFile.Filename))
+	}
+
+	// The demo of a duplicate gets parsed for the match it has been merged into.
+	if !a.mergeDuplicates(ctx, m) {
+		return nil
+	}
+
+	if err := parser.Parse(ctx, a.DemoStore, demoFile); err != nil {
+		log.Error(err)
+		return err
+	}
+
+	if !parser.GameOver {
+		const msg = "demoparser: game %v did not finish before parsing ended. The file might be incomplete"
+		log.Errorf(msg, demoFile.Filename)
+		return job.Permanent(fmt.Errorf(msg, demoFile.Filename))
+	}
+
+	if a.ConfigService.GetConfig().Parser.ReplayTickInterval > 0 {
+		if err := parser.WriteReplays(ctx, a.DemoStore, demoFile.Filename); err != nil {
+			log.Error(err)
+		}
+	}
+
+	firstTimeParsing := m.Status != match.Parsed
+
+	result := match.CreateResult(parser.Match, a.tradeWindow())
+	if err := a.MatchService.UpdateResult(ctx, m, result, ParserVersion); err != nil {
+		log.Error(err)
+		return err
+	}
+
+	if err := a.HeatmapService.SaveHeatmap(ctx, heatmap.CreateHeatmap(parser.Match)); err != nil {
+		log.Error(err)
+	}
+
+	for _, t := range m.Result.Teams {
+		for _, playerResult := range t.Players {
+			player, err := a.PlayerService.GetPlayer(ctx, playerResult.SteamID)
+			if err != nil {
+				const msg = "demoparser: unable to query player: %s"
+				log.Errorf(msg, err)
+				continue
+			}
+
+			playerResult.MatchRounds = byte(len(m.Result.Rounds))
+			playerResult.ScoreOwnTeam = t.Wins
+
+			// This gets the team index in the array by turning the index around.
+			// There could be a smarter way, but this is a fast one.
+			enemyTeamId := (t.TeamID + 1) % 2
+			playerResult.ScoreEnemyTeam = m.Result.Teams[enemyTeamId].Wins
+			if err := a.PlayerService.AddResult(ctx, player, playerResult); err != nil {
+				log.Error(err)
+			}
+		}
+	}
+
+	const msg = "demoparser: finished parsing %s"
+	log.Infof(msg, filename)
+
+	if a.ConfigService.GetConfig().Discord.Enabled && firstTimeParsing {
+		a.publishGameResultToDiscord(result)
+	}
+
+	return nil
+}
+
+// Deletes the demo files that are no longer kept according to the configured retention rules.
+func (a *App) enforceRetention(ctx context.Context) {
+	storageConfig := a.ConfigService.GetConfig().Storage
+	if storageConfig == nil {
+		return
+	}
+
+	a.deleteExpiredDemos(ctx, storageConfig)
+
+	if storageConfig.Compress {
+		a.archiveDemos(ctx)
+	}
+}
+
+// Deletes the demos according to the retention rules.
+func (a *App) deleteExpiredDemos(ctx context.Context, storageConfig *config.StorageConfig) {
+	policy := &match.RetentionPolicy{
+		MaxAge:       time.Duration(storageConfig.RetentionDays) * 24 * time.Hour,
+		KeepPerUser:  storageConfig.KeepPerUser,
+		MaxDiskUsage: int64(storageConfig.MaxDiskUsage) * 1024 * 1024,
+	}
+
+	if storageConfig.KeepParsedWithCurrentVersion {
+		policy.KeepParserVersion = ParserVersion
+	}
+
+	if policy.MaxAge == 0 && policy.KeepPerUser == 0 && policy.MaxDiskUsage == 0 {
+		return
+	}
+
+	if policy.KeepPerUser > 0 {
+		users, err := a.UserService.GetAll(ctx)
+		if err != nil {
+			log.Error(err)
+			return
+		}
+
+		for _, u := range users {
+			if u.Steam != nil {
+				policy.UserIDs = append(policy.UserIDs, u.Steam.ID)
+			}
+		}
+	}
+
+	matches, err := a.MatchService.GetAll(ctx)
+	if err != nil {
+		log.Error(err)
+		return
+	}
+
+	sizes := make(map[string]int64)
+	for _, m := range matches {
+		if m.Filename == "" || m.FileState == match.FileDeleted {
+			continue
+		}
+
+		if info, err := a.DemoStore.Stat(ctx, m.Filename); err == nil {
+			sizes[m.Filename] = info.Size
+		}
+	}
+
+	for _, m := range match.SelectExpiredDemos(matches, sizes, policy, time.Now()) {
+		if err := a.DemoStore.Delete(ctx, m.Filename); err != nil {
+			log.Error(err)
+			continue
+		}
+
+		if err := a.MatchService.SetFileState(ctx, m, match.FileDeleted, m.Filename); err != nil {
+			log.Error(err)
+		}
+
+		const msg = "demoparser: deleted demo %s due to the retention rules"
+		log.Infof(msg, m.Filename)
+	}
+}
+
+// Compresses the uncompressed demos of parsed matches, e.g. manual uploads or demos downloaded before compression was
+// enabled. Demos that are going to be parsed again are archived afterwards, thus they are not replaced while parsing.
+func (a *App) archiveDemos(ctx context.Context) {
+	matches, err := a.MatchService.GetAllParsed(ctx)
+	if err != nil {
+		log.Error(err)
+		return
+	}
+
+	for _, m := range matches {
+		if ctx.Err() != nil {
+			return
+		}
+
+		if m.FileState == match.FileDeleted || match.FileStateOf(m.Filename) != match.FilePresent {
+			continue
+		}
+
+		if m.Result == nil || m.Result.ParserVersion != ParserVersion {
+			continue
+		}
+
+		filename := m.Filename
+		archiveFilename, err := demo.Archive(ctx, a.DemoStore, filename)
+		if err != nil {
+			log.Error(err)
+			continue
+		}
+
+		if err := a.MatchService.SetFileState(ctx, m, match.FileArchived, archiveFilename); err != nil {
+			log.Error(err)
+			continue
+		}
+
+		const msg = "demoparser: archived demo %s as %s"
+		log.Infof(msg, filename, archiveFilename)
+	}
+}
+
+// Returns the configured trade window or the default one if it is not set.
+func (a *App) tradeWindow() time.Duration {
+	seconds := a.ConfigService.GetConfig().Parser.TradeWindow
+	if seconds <= 0 {
+		return match.DefaultTradeWindow
+	}
+
+	return time.Duration(seconds) * time.Second
+}
+
+func (a *App) publishGameResultToDiscord(result *match.MatchResult) {
+	message := fmt.Sprintf("New match result: \nMap **%s**: *%d - %d*\n", result.Map, result.Teams[0].Wins, result.Teams[1].Wins)
+	for _, t := range result.Teams {
+		message += fmt.Sprintf("Team %d with **%d** wins\n", t.TeamID, t.Wins)
+		for _, p := range t.Players {
+			message += fmt.Sprintf("Player `%s`: *%d-%d*\n", p.Name, p.Kills, p.Deaths)
+		}
+		message += "\n"
+	}
+
+	a.DiscordService.SendMessage(message, a.ConfigService.GetConfig().Discord.ChannelID)
+}
@@ -0,0 +1,138 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/Cludch/csgo-tools/internal/domain/job"
+	"github.com/Cludch/csgo-tools/internal/domain/match"
+	"github.com/Cludch/csgo-tools/pkg/faceitapi"
+	log "github.com/sirupsen/logrus"
+)
+
+// Interval in which the APIs are checked for new matches.
+const pollInterval = time.Minute
+
+// PollValve checks Valve's game history API for new share codes each minute.
+func PollValve(a *App, s *Scheduler) {
+	s.Every("poll-valve", pollInterval, a.pollValve)
+}
+
+// PollFaceit checks Faceit's API for new matches each minute.
+func PollFaceit(a *App, s *Scheduler) {
+	s.Every("poll-faceit", pollInterval, a.pollFaceit)
+}
+
+// Requests the next share code for the latest share code of each csgo user.
+func (a *App) pollValve(ctx context.Context) {
+	a.expireMatches(ctx)
+
+	users, err := a.UserService.GetUsersWithAuthenticationCode(ctx)
+	if err != nil {
+		log.Error(err)
+		return
+	}
+
+	for _, u := range users {
+		sc, err := a.UserService.QueryLatestShareCode(ctx, u)
+		if err != nil {
+			log.Error(err)
+		}
+
+		if sc == nil {
+			continue
+		}
+
+		m, err := a.MatchService.CreateMatchFromSharecode(ctx, sc)
+		if err != nil {
+			const msg = "unable to create match from sharecode %s"
+			log.Errorf(msg, err)
+			continue
+		}
+
+		// Request the download url from the GameCoordinator.
+		if m.DownloadURL == "" {
+			if err := a.JobService.Enqueue(ctx, job.GCLookup, m.ID, job.DefaultMaxAttempts); err != nil {
+				log.Error(err)
+			}
+		}
+
+		if err = a.UserService.UpdateLatestShareCode(ctx, u, sc); err != nil {
+			const msg = "unable to update user latest share code %s"
+			log.Errorf(msg, err)
+			continue
+		}
+	}
+}
+
+// Marks the matchmaking matches as expired whose demos have been deleted by Valve before they were downloaded.
+func (a *App) expireMatches(ctx context.Context) {
+	expired, total, err := a.MatchService.ExpireMatches(ctx)
+	if err != nil {
+		log.Error(err)
+		return
+	}
+
+	if expired > 0 {
+		const msg = "%d matchmaking demos expired before they were downloaded, %d demos have been lost to expiry in total"
+		log.Warnf(msg, expired, total)
+	}
+}
+
+// Requests the match history and the match details of each faceit user.
+// This is by no means efficient
+func (a *App) pollFaceit(ctx context.Context) {
+	faceitAPIKey := a.ConfigService.GetConfig().Faceit.FaceitAPIKey
+
+	users, err := a.UserService.GetUsersWithFaceitId(ctx)
+	if err != nil {
+		log.Error(err)
+		return
+	}
+
+	for _, u := range users {
+		playerMatchHistory, err := faceitapi.GetPlayerMatchHistory(ctx, faceitAPIKey, u.Faceit.ID)
+		if err != nil {
+			log.Error(err)
+			continue
+		}
+
+		if playerMatchHistory.Result == nil {
+			continue
+		}
+
+		for _, matchHistory := range *playerMatchHistory.Result {
+			matchId := matchHistory.MatchId
+			matchDetails, err := faceitapi.GetMatchDetails(ctx, faceitAPIKey, matchId)
+
+			if err != nil {
+				log.Error(err)
+			}
+
+			if matchDetails == nil || matchDetails.Status != "FINISHED" || len(matchDetails.DemoUrl) == 0 {
+				continue
+			}
+
+			downloadUrl := matchDetails.DemoUrl[0]
+			startTime := time.Unix(matchDetails.StartTime, 0)
+			m, err := a.MatchService.CreateDownloadableMatchFromFaceitId(ctx, matchId, downloadUrl, startTime)
+			if err != nil {
+				const msg = "unable to create match downloadable faceit match for id %s and url %s: %s"
+				log.Errorf(msg, matchId, downloadUrl, err)
+				continue
+			}
+
+			// Matches that have been downloaded already are returned as they are.
+			if m.Status != match.Downloadable {
+				continue
+			}
+
+			if err := a.JobService.Enqueue(ctx, job.Download, m.ID, a.ConfigService.GetDownloadConfig().MaxAttempts); err != nil {
+				log.Error(err)
+				continue
+			}
+
+			log.Infof("created downloadable faceit match for id %s", matchId)
+		}
+	}
+}
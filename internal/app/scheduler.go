@@ -0,0 +1,109 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Cludch/csgo-tools/internal/domain/match"
+	log "github.com/sirupsen/logrus"
+)
+
+// DefaultRestartDelay is the time the scheduler waits before restarting a failed task.
+const DefaultRestartDelay = 30 * time.Second
+
+// Scheduler runs the periodic tasks and the long running workers of all components started in one process.
+// Tasks that fail or panic are restarted after the restart delay until the context passed to Run is cancelled.
+type Scheduler struct {
+	RestartDelay time.Duration
+	tasks        []*task
+	// component is the name of the component currently registering its tasks.
+	component string
+}
+
+type task struct {
+	name string
+	// component is recorded as actor of the status changes made by the task.
+	component string
+	run       func(ctx context.Context) error
+}
+
+func NewScheduler() *Scheduler {
+	return &Scheduler{RestartDelay: DefaultRestartDelay}
+}
+
+// Every schedules the function to run right away and then once per interval. Runs of the same task never overlap.
+func (s *Scheduler) Every(name string, interval time.Duration, fn func(ctx context.Context)) {
+	s.Go(name, func(ctx context.Context) error {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+
+		for {
+			fn(ctx)
+
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-t.C:
+			}
+		}
+	})
+}
+
+// Go schedules the function to run in its own goroutine, e.g. a job worker or a server. The function should return
+// once the context is cancelled. It is restarted if it returns an error before.
+func (s *Scheduler) Go(name string, fn func(ctx context.Context) error) {
+	s.tasks = append(s.tasks, &task{name: name, component: s.component, run: fn})
+}
+
+// Run starts all scheduled tasks and blocks until they returned, which they do once the context is cancelled.
+func (s *Scheduler) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, t := range s.tasks {
+		const msg = "scheduler: starting %s"
+		log.Debugf(msg, t.name)
+
+		wg.Add(1)
+		go func(t *task) {
+			defer wg.Done()
+			s.supervise(ctx, t)
+		}(t)
+	}
+
+	wg.Wait()
+}
+
+// Runs the task and restarts it after the restart delay as long as it fails before the context is cancelled.
+func (s *Scheduler) supervise(ctx context.Context, t *task) {
+	if t.component != "" {
+		ctx = match.WithActor(ctx, t.component)
+	}
+
+	for {
+		err := t.safeRun(ctx)
+		if err == nil || ctx.Err() != nil {
+			return
+		}
+
+		const msg = "scheduler: %s failed, restarting in %s: %s"
+		log.Errorf(msg, t.name, s.RestartDelay, err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(s.RestartDelay):
+		}
+	}
+}
+
+// Runs the task and turns a panic into an error.
+func (t *task) safeRun(ctx context.Context) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return t.run(ctx)
+}
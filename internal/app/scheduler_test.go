@@ -0,0 +1,103 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Cludch/csgo-tools/internal/app"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchedulerRunWaitsForAllTasks(t *testing.T) {
+	s := app.NewScheduler()
+
+	var done int32
+	for i := 0; i < 3; i++ {
+		s.Go("task", func(ctx context.Context) error {
+			time.Sleep(10 * time.Millisecond)
+			atomic.AddInt32(&done, 1)
+			return nil
+		})
+	}
+
+	s.Run(context.Background())
+	assert.Equal(t, int32(3), atomic.LoadInt32(&done))
+}
+
+func TestSchedulerRunStopsOnCancel(t *testing.T) {
+	s := app.NewScheduler()
+	s.Every("task", time.Millisecond, func(ctx context.Context) {})
+	s.Go("worker", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan bool)
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("scheduler did not stop")
+	}
+}
+
+func TestSchedulerRestartsFailedTasks(t *testing.T) {
+	s := app.NewScheduler()
+	s.RestartDelay = time.Millisecond
+
+	var runs int32
+	s.Go("task", func(ctx context.Context) error {
+		switch atomic.AddInt32(&runs, 1) {
+		case 1:
+			return errors.New("failed")
+		case 2:
+			panic("failed")
+		default:
+			return nil
+		}
+	})
+
+	s.Run(context.Background())
+	assert.Equal(t, int32(3), atomic.LoadInt32(&runs))
+}
+
+func TestSchedulerEveryRunsRightAway(t *testing.T) {
+	s := app.NewScheduler()
+
+	ran := make(chan bool, 1)
+	s.Every("task", time.Hour, func(ctx context.Context) {
+		select {
+		case ran <- true:
+		default:
+		}
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx)
+
+	select {
+	case <-ran:
+	case <-time.After(time.Second):
+		t.Fatal("task did not run")
+	}
+}
+
+func TestCommandsEndWithAll(t *testing.T) {
+	commands := app.Commands()
+
+	assert.Len(t, commands, len(app.Components)+1)
+	assert.Equal(t, app.All, commands[len(commands)-1])
+	for _, command := range commands[:len(commands)-1] {
+		assert.Contains(t, app.Components, command)
+	}
+}
@@ -41,7 +41,7 @@ func (c *Controller) Callback(g *gin.Context) {
 		return
 	}
 
-	token, err := c.service.HandleAuth(user)
+	token, err := c.service.HandleAuth(g.Request.Context(), user)
 
 	if err != nil {
 		_ = g.AbortWithError(http.StatusInternalServerError, err)
@@ -81,7 +81,7 @@ func (c *Controller) GetUserDetails(g *gin.Context) {
 	}
 
 	parsedUserId, _ := entity.StringToID(fmt.Sprint(userId))
-	user, err := c.userService.GetUser(parsedUserId)
+	user, err := c.userService.GetUser(g.Request.Context(), parsedUserId)
 	if err != nil {
 		log.Errorf("error while fetching user details %e", err)
 		g.AbortWithStatus(http.StatusInternalServerError)
@@ -1,10 +1,12 @@
 package auth
 
 import (
+	"context"
+
 	"github.com/markbates/goth"
 )
 
 type UseCase interface {
-	HandleAuth(user goth.User) (string, error)
+	HandleAuth(ctx context.Context, user goth.User) (string, error)
 	ValidateToken(encodedToken string) (*Claims, error)
 }
@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"time"
@@ -31,7 +32,7 @@ func NewService(c config.UseCase, u user.UseCase) *Service {
 	}
 }
 
-func (s *Service) HandleAuth(gothUser goth.User) (string, error) {
+func (s *Service) HandleAuth(ctx context.Context, gothUser goth.User) (string, error) {
 	userId := gothUser.UserID
 	provider := gothUser.Provider
 
@@ -40,7 +41,7 @@ func (s *Service) HandleAuth(gothUser goth.User) (string, error) {
 
 	if provider == "steam" {
 		steamId, _ := strconv.ParseUint(userId, 10, 64)
-		dbUser, err = s.userService.SigninUsingSteam(steamId, gothUser.Name)
+		dbUser, err = s.userService.SigninUsingSteam(ctx, steamId, gothUser.Name)
 	} else {
 		return "", fmt.Errorf("unknown authentication provider: %s", provider)
 	}
@@ -3,6 +3,7 @@ package config
 // UseCase defines the config service functions.
 type UseCase interface {
 	GetConfig() *Config
+	GetDownloadConfig() *DownloadConfig
 	IsDebug() bool
 	IsTrace() bool
 }
@@ -11,7 +11,8 @@ type Service struct {
 	config *Config
 }
 
-func NewService() *Service {
+// NewService reads the configuration from the config file and the environment.
+func NewService() (*Service, error) {
 	service := Service{}
 
 	replacer := strings.NewReplacer(".", "_")
@@ -25,22 +26,23 @@ func NewService() *Service {
 	viper.AutomaticEnv()
 
 	if err := viper.ReadInConfig(); err != nil {
-		log.Panic(err)
+		return nil, err
 	}
 
 	service.config = &Config{}
 	if err := viper.Unmarshal(&service.config); err != nil {
-		log.Panic(err)
+		return nil, err
 	}
 
 	service.setLoggingLevel()
 
-	return &service
+	return &service, nil
 }
 
 // Config holds the application configuration.
 type Config struct {
 	DemosDir string          `mapstructure:"demosDir"`
+	RadarDir string          `mapstructure:"radarDir"`
 	Auth     *AuthConfig     `mapstructure:"auth"`
 	Steam    *SteamConfig    `mapstructure:"steam"`
 	Faceit   *FaceitConfig   `mapstructure:"faceit"`
@@ -48,6 +50,8 @@ type Config struct {
 	Database *DatabaseConfig `mapstructure:"database"`
 	Debug    string          `mapstructure:"debug"`
 	Parser   *ParserConfig   `mapstructure:"parser"`
+	Storage  *StorageConfig  `mapstructure:"storage"`
+	Download *DownloadConfig `mapstructure:"download"`
 }
 
 // AuthConfig contains the host url for the authentication callback.
@@ -85,8 +89,55 @@ type DatabaseConfig struct {
 	Database string `mapstructure:"database"`
 }
 
+// ParserConfig holds the amount of parallel parser workers and the time window in seconds in which a kill counts as trade.
+// Player positions are sampled every PositionSampleInterval milliseconds for heatmaps and replay frames are
+// written every ReplayTickInterval ticks. Both are disabled when set to 0.
 type ParserConfig struct {
-	WorkerCount string `mapstructure:"workerCount"`
+	WorkerCount            string `mapstructure:"workerCount"`
+	TradeWindow            int    `mapstructure:"tradeWindow"`
+	PositionSampleInterval int    `mapstructure:"positionSampleInterval"`
+	ReplayTickInterval     int    `mapstructure:"replayTickInterval"`
+}
+
+// DownloadConfig defines how many demos are downloaded in parallel and how failed downloads are retried.
+// Failed downloads are retried after Backoff seconds, doubling with every attempt, until MaxAttempts are reached.
+// The request intervals are the minimum milliseconds between two requests to the replay servers of Valve or Faceit.
+// Matchmaking demos older than ExpiryDays have been deleted by Valve and are no longer requested.
+type DownloadConfig struct {
+	WorkerCount           int `mapstructure:"workerCount"`
+	MaxAttempts           int `mapstructure:"maxAttempts"`
+	Backoff               int `mapstructure:"backoff"`
+	ValveRequestInterval  int `mapstructure:"valveRequestInterval"`
+	FaceitRequestInterval int `mapstructure:"faceitRequestInterval"`
+	ExpiryDays            int `mapstructure:"expiryDays"`
+}
+
+// StorageConfig defines where demos are stored, whether downloaded demos get compressed and how long demos are kept.
+// Demos are stored in the demos dir unless the backend is set to s3. Partial downloads are kept in DownloadDir or in
+// the demos dir if it is not set.
+// A demo is kept if it is younger than RetentionDays, one of the latest KeepPerUser demos of a user or parsed with
+// the current parser version if KeepParsedWithCurrentVersion is set. The oldest demos are deleted once the demos use
+// more than MaxDiskUsage megabytes. All rules are disabled when set to their zero value.
+type StorageConfig struct {
+	Backend                      string    `mapstructure:"backend"`
+	S3                           *S3Config `mapstructure:"s3"`
+	DownloadDir                  string    `mapstructure:"downloadDir"`
+	Compress                     bool      `mapstructure:"compress"`
+	RetentionDays                int       `mapstructure:"retentionDays"`
+	KeepPerUser                  int       `mapstructure:"keepPerUser"`
+	KeepParsedWithCurrentVersion bool      `mapstructure:"keepParsedWithCurrentVersion"`
+	MaxDiskUsage                 int       `mapstructure:"maxDiskUsage"`
+}
+
+// S3Config holds the connection information of an S3-compatible object store like AWS S3 or MinIO.
+type S3Config struct {
+	Endpoint  string `mapstructure:"endpoint"`
+	Region    string `mapstructure:"region"`
+	Bucket    string `mapstructure:"bucket"`
+	Prefix    string `mapstructure:"prefix"`
+	AccessKey string `mapstructure:"accessKey"`
+	SecretKey string `mapstructure:"secretKey"`
+	UseSSL    bool   `mapstructure:"useSSL"`
 }
 
 // GetConfig returns the application configuration.
@@ -94,6 +145,40 @@ func (s *Service) GetConfig() *Config {
 	return s.config
 }
 
+// Default values of the download config.
+const (
+	defaultDownloadWorkerCount = 2
+	defaultDownloadMaxAttempts = 5
+	defaultDownloadBackoff     = 60
+	defaultDownloadExpiryDays  = 30
+)
+
+// GetDownloadConfig returns the download config with defaults for all unset values.
+func (s *Service) GetDownloadConfig() *DownloadConfig {
+	c := &DownloadConfig{}
+	if configured := s.GetConfig().Download; configured != nil {
+		*c = *configured
+	}
+
+	if c.WorkerCount <= 0 {
+		c.WorkerCount = defaultDownloadWorkerCount
+	}
+
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = defaultDownloadMaxAttempts
+	}
+
+	if c.Backoff <= 0 {
+		c.Backoff = defaultDownloadBackoff
+	}
+
+	if c.ExpiryDays <= 0 {
+		c.ExpiryDays = defaultDownloadExpiryDays
+	}
+
+	return c
+}
+
 // IsDebug returns whether the application is in debug mode.
 func (s *Service) IsDebug() bool {
 	return s.GetConfig().Debug == "true" || s.IsTrace()
@@ -14,10 +14,11 @@ func (s *Service) handleMatchStart(e events.MatchStart) {
 	s.Match.Map = s.Match.Header.MapName
 	s.SidesSwitched = false
 	s.GameOver = false
+	s.detectFormat()
 
 	if s.configurationService.IsDebug() {
-		const msg = "Game started on map %v"
-		s.debug(fmt.Sprintf(msg, s.Match.Map))
+		const msg = "Game started on map %v (%v, %d rounds)"
+		s.debug(fmt.Sprintf(msg, s.Match.Map, s.Match.Format, s.Match.MaxRounds))
 	}
 
 	gameState := s.parser.GameState()
@@ -54,6 +55,9 @@ func (s *Service) handleGamePhaseChanged(e events.GamePhaseChanged) {
 	case common.GamePhaseGameEnded:
 		s.Match.Duration = s.parser.CurrentTime()
 		s.GameOver = true
+
+		// Convars might not have been sent yet when the match started.
+		s.detectFormat()
 	}
 }
 
@@ -65,11 +69,57 @@ func (s *Service) handleRoundStart(e events.RoundStart) {
 	s.CurrentRound++
 	s.RoundOngoing = true
 	s.RoundStart = s.parser.CurrentTime()
-	s.Match.Rounds = append(s.Match.Rounds, &Round{})
+
+	round := &Round{StartTick: s.RoundStart}
+	round.Half, round.Overtime, round.IsPistolRound = GetHalf(s.parser.GameState().TotalRoundsPlayed(), s.maxRounds(), s.overtimeMaxRounds())
+	s.Match.Rounds = append(s.Match.Rounds, round)
 
 	if s.configurationService.IsDebug() {
-		const msg = "Starting round %d"
-		s.debug(fmt.Sprintf(msg, s.CurrentRound))
+		const msg = "Starting round %d in half %d (pistol round: %v)"
+		s.debug(fmt.Sprintf(msg, s.CurrentRound, round.Half, round.IsPistolRound))
+	}
+}
+
+// Saves the equipment value and money of each player and team as soon as the round goes live.
+func (s *Service) handleFreezetimeEnd(e events.RoundFreezetimeEnd) {
+	if s.parser.GameState().IsWarmupPeriod() || !s.RoundOngoing {
+		return
+	}
+
+	round := s.Match.Rounds[s.CurrentRound-1]
+	round.FreezetimeEndTick = s.parser.CurrentTime()
+	gameState := s.parser.GameState()
+
+	for _, p := range gameState.Participants().Playing() {
+		if p.IsBot {
+			continue
+		}
+
+		player, err := s.getPlayer(p)
+		if err != nil {
+			log.Error(err)
+			continue
+		}
+
+		round.Economy = append(round.Economy, &Economy{Player: player, EquipmentValue: p.EquipmentValueFreezeTimeEnd(),
+			Money: p.Money(), MoneySpent: p.MoneySpentThisRound()})
+	}
+
+	for _, state := range []*common.TeamState{gameState.TeamTerrorists(), gameState.TeamCounterTerrorists()} {
+		money := 0
+		for _, member := range state.Members() {
+			money += member.Money()
+		}
+
+		teamEconomy := &TeamEconomy{Team: s.Match.Teams[GetTeamIndex(state.Team(), s.SidesSwitched)],
+			EquipmentValue: state.FreezeTimeEndEquipmentValue(), Money: money, MoneySpent: state.MoneySpentThisRound()}
+		teamEconomy.BuyType = ClassifyBuy(teamEconomy.EquipmentValue, money, len(state.Members()))
+		round.Teams = append(round.Teams, teamEconomy)
+
+		if s.configurationService.IsDebug() {
+			const msg = "Team %v has equipment worth %d (%v)"
+			s.debug(fmt.Sprintf(msg, state.Team(), teamEconomy.EquipmentValue, teamEconomy.BuyType))
+		}
 	}
 }
 
@@ -101,8 +151,13 @@ func (s *Service) handleRoundEnd(e events.RoundEnd) {
 	}
 
 	round.Winner = s.Match.Teams[GetTeamIndex(e.Winner, s.SidesSwitched)]
+	round.WinnerSide = e.Winner
 	round.WinReason = e.Reason
 	round.Duration = s.parser.CurrentTime() - s.RoundStart
+
+	for _, clutch := range round.Clutches {
+		clutch.Won = clutch.Player.Team == round.Winner
+	}
 }
 
 func (s *Service) handleKill(e events.Kill) {
@@ -118,6 +173,8 @@ func (s *Service) handleKill(e events.Kill) {
 	victim, err := s.getPlayer(e.Victim)
 	if err == nil {
 		kill.Victim = victim
+		kill.VictimPosition = NewPosition(e.Victim.Position())
+		kill.VictimSide = e.Victim.Team
 	}
 
 	// Add optional killer if player died e.g. through fall damage.
@@ -125,6 +182,8 @@ func (s *Service) handleKill(e events.Kill) {
 		killer, err := s.getPlayer(e.Killer)
 		if err == nil {
 			kill.Killer = killer
+			kill.KillerPosition = NewPosition(e.Killer.Position())
+			kill.KillerSide = e.Killer.Team
 		}
 	}
 
@@ -137,6 +196,46 @@ func (s *Service) handleKill(e events.Kill) {
 	}
 
 	round.Kills = append(round.Kills, kill)
+
+	if s.RoundOngoing {
+		s.detectClutches(round, e.Victim)
+	}
+}
+
+// Checks whether the kill left only one player alive on a side and records the clutch.
+// A side can only enter one clutch per round, but both sides can be in one at the same time (e.g. 1v1).
+func (s *Service) detectClutches(round *Round, victim *common.Player) {
+	gameState := s.parser.GameState()
+	terrorists := AlivePlayers(gameState.TeamTerrorists(), victim)
+	counterTerrorists := AlivePlayers(gameState.TeamCounterTerrorists(), victim)
+
+	s.addClutch(round, terrorists, counterTerrorists)
+	s.addClutch(round, counterTerrorists, terrorists)
+}
+
+func (s *Service) addClutch(round *Round, alive []*common.Player, opponents []*common.Player) {
+	if len(alive) != 1 || len(opponents) == 0 || alive[0].IsBot {
+		return
+	}
+
+	player, err := s.getPlayer(alive[0])
+	if err != nil {
+		log.Error(err)
+		return
+	}
+
+	for _, clutch := range round.Clutches {
+		if clutch.Player.Team == player.Team {
+			return
+		}
+	}
+
+	if s.configurationService.IsDebug() {
+		const msg = "%v is in a 1v%d clutch"
+		s.debug(fmt.Sprintf(msg, player.Name, len(opponents)))
+	}
+
+	round.Clutches = append(round.Clutches, &Clutch{Player: player, Opponents: byte(len(opponents))})
 }
 
 func (s *Service) handlePlayerHurt(e events.PlayerHurt) {
@@ -145,7 +244,12 @@ func (s *Service) handlePlayerHurt(e events.PlayerHurt) {
 	}
 
 	round := s.Match.Rounds[s.CurrentRound-1]
-	damage := &Damage{HealthDamageTaken: e.HealthDamageTaken}
+	damage := &Damage{Tick: s.parser.CurrentTime(), HitGroup: e.HitGroup, HealthDamageTaken: e.HealthDamageTaken,
+		ArmorDamageTaken: e.ArmorDamageTaken}
+
+	if e.Weapon != nil {
+		damage.Weapon = e.Weapon.Type
+	}
 
 	if e.Attacker != nil {
 		attacker, err := s.getPlayer(e.Attacker)
@@ -154,9 +258,197 @@ func (s *Service) handlePlayerHurt(e events.PlayerHurt) {
 		}
 	}
 
+	if e.Player != nil {
+		victim, err := s.getPlayer(e.Player)
+		if err == nil {
+			damage.Victim = victim
+		}
+	}
+
 	round.Damage = append(round.Damage, damage)
 }
 
+// Saves the shots of guns in order to calculate the accuracy. Knives and grenades are ignored.
+func (s *Service) handleWeaponFire(e events.WeaponFire) {
+	if s.parser.GameState().IsWarmupPeriod() || s.CurrentRound == 0 {
+		return
+	}
+
+	if e.Shooter == nil || e.Weapon == nil || !IsGun(e.Weapon.Type) {
+		return
+	}
+
+	shooter, err := s.getPlayer(e.Shooter)
+	if err != nil {
+		return
+	}
+
+	round := s.Match.Rounds[s.CurrentRound-1]
+	round.Shots = append(round.Shots, &Shot{Tick: s.parser.CurrentTime(), Shooter: shooter, Weapon: e.Weapon.Type})
+}
+
+func (s *Service) handleGrenadeThrow(e events.GrenadeProjectileThrow) {
+	if s.parser.GameState().IsWarmupPeriod() || s.CurrentRound == 0 {
+		return
+	}
+
+	if e.Projectile == nil || e.Projectile.Thrower == nil || e.Projectile.WeaponInstance == nil {
+		return
+	}
+
+	thrower, err := s.getPlayer(e.Projectile.Thrower)
+	if err != nil {
+		return
+	}
+
+	round := s.Match.Rounds[s.CurrentRound-1]
+	round.Utility = append(round.Utility, &Utility{Tick: s.parser.CurrentTime(), Thrower: thrower, Type: e.Projectile.WeaponInstance.Type})
+}
+
+func (s *Service) handlePlayerFlashed(e events.PlayerFlashed) {
+	if s.parser.GameState().IsWarmupPeriod() || s.CurrentRound == 0 {
+		return
+	}
+
+	// Dead players and spectators are flashed as well but this should not count.
+	if e.Player == nil || e.Attacker == nil || e.Player == e.Attacker || !e.Player.IsAlive() {
+		return
+	}
+
+	attacker, err := s.getPlayer(e.Attacker)
+	if err != nil {
+		return
+	}
+
+	victim, err := s.getPlayer(e.Player)
+	if err != nil {
+		return
+	}
+
+	round := s.Match.Rounds[s.CurrentRound-1]
+	round.Flashes = append(round.Flashes, &Flash{Tick: s.parser.CurrentTime(), Attacker: attacker, Victim: victim, Duration: e.FlashDuration()})
+}
+
+// Samples the position of all alive players once per configured interval during the round.
+func (s *Service) handleFrameDone(e events.FrameDone) {
+	if s.parser.GameState().IsWarmupPeriod() || !s.RoundOngoing {
+		return
+	}
+
+	now := s.parser.CurrentTime()
+	if now-s.lastPositionSample < s.positionSampleInterval() {
+		return
+	}
+	s.lastPositionSample = now
+
+	round := s.Match.Rounds[s.CurrentRound-1]
+	for _, p := range s.parser.GameState().Participants().Playing() {
+		if p.IsBot || !p.IsAlive() {
+			continue
+		}
+
+		player, err := s.getPlayer(p)
+		if err != nil {
+			continue
+		}
+
+		round.Positions = append(round.Positions, &PositionSample{Tick: now, Player: player, Position: NewPosition(p.Position())})
+	}
+}
+
+// Returns the bomb of the current round and creates it if it does not exist yet.
+// Returns nil if there is no ongoing round.
+func (s *Service) currentBomb() *Bomb {
+	if s.parser.GameState().IsWarmupPeriod() || s.CurrentRound == 0 || !s.RoundOngoing {
+		return nil
+	}
+
+	round := s.Match.Rounds[s.CurrentRound-1]
+	if round.Bomb == nil {
+		round.Bomb = &Bomb{}
+	}
+
+	return round.Bomb
+}
+
+func (s *Service) handleBombPlantBegin(e events.BombPlantBegin) {
+	bomb := s.currentBomb()
+	if bomb == nil || bomb.IsPlanted {
+		return
+	}
+
+	bomb.PlantStartTick = s.parser.CurrentTime()
+}
+
+func (s *Service) handleBombPlanted(e events.BombPlanted) {
+	bomb := s.currentBomb()
+	if bomb == nil {
+		return
+	}
+
+	bomb.IsPlanted = true
+	bomb.PlantTick = s.parser.CurrentTime()
+	if e.Site != events.BomsiteUnknown {
+		bomb.Site = string(rune(e.Site))
+	}
+
+	if e.Player != nil {
+		planter, err := s.getPlayer(e.Player)
+		if err == nil {
+			bomb.Planter = planter
+		}
+	}
+
+	if s.configurationService.IsDebug() {
+		const msg = "Bomb planted on site %v"
+		s.debug(fmt.Sprintf(msg, bomb.Site))
+	}
+}
+
+// Saves the last defuse attempt and whether the defuser has a kit.
+func (s *Service) handleBombDefuseStart(e events.BombDefuseStart) {
+	bomb := s.currentBomb()
+	if bomb == nil {
+		return
+	}
+
+	bomb.DefuseStartTick = s.parser.CurrentTime()
+	bomb.HasKit = e.HasKit
+
+	if e.Player != nil {
+		defuser, err := s.getPlayer(e.Player)
+		if err == nil {
+			bomb.Defuser = defuser
+		}
+	}
+}
+
+func (s *Service) handleBombDefused(e events.BombDefused) {
+	bomb := s.currentBomb()
+	if bomb == nil {
+		return
+	}
+
+	bomb.IsDefused = true
+	bomb.DefuseTick = s.parser.CurrentTime()
+
+	if e.Player != nil {
+		defuser, err := s.getPlayer(e.Player)
+		if err == nil {
+			bomb.Defuser = defuser
+		}
+	}
+}
+
+func (s *Service) handleBombExplode(e events.BombExplode) {
+	bomb := s.currentBomb()
+	if bomb == nil {
+		return
+	}
+
+	bomb.IsExploded = true
+}
+
 func (s *Service) handleRankUpdate(e events.RankUpdate) {
 	player, err := s.getPlayer(e.Player)
 	if err != nil {
@@ -0,0 +1,90 @@
+package demoparser
+
+import (
+	"testing"
+	"time"
+
+	"github.com/markus-wa/demoinfocs-golang/v2/pkg/demoinfocs/common"
+	"github.com/markus-wa/demoinfocs-golang/v2/pkg/demoinfocs/events"
+	"github.com/markus-wa/demoinfocs-golang/v2/pkg/demoinfocs/fake"
+	st "github.com/markus-wa/demoinfocs-golang/v2/pkg/demoinfocs/sendtables"
+	stfake "github.com/markus-wa/demoinfocs-golang/v2/pkg/demoinfocs/sendtables/fake"
+	"github.com/stretchr/testify/assert"
+)
+
+// Creates a service within the first round of a match with one player per team.
+func newUtilityService() *Service {
+	gameState := new(fake.GameState)
+	gameState.On("IsWarmupPeriod").Return(false)
+
+	parser := fake.NewParser()
+	parser.On("GameState").Return(gameState)
+	parser.On("CurrentTime").Return(5 * time.Second)
+
+	t := &Team{StartedAs: common.TeamTerrorists}
+	ct := &Team{StartedAs: common.TeamCounterTerrorists}
+	players := []*Player{{SteamID: 1, Name: "t", Team: t}, {SteamID: 2, Name: "ct", Team: ct}}
+	t.Players = players[:1]
+	ct.Players = players[1:]
+
+	return &Service{
+		parser:       parser,
+		Match:        &MatchData{Players: players, Teams: [2]*Team{t, ct}, Rounds: []*Round{{}}},
+		CurrentRound: 1,
+	}
+}
+
+func TestHandleGrenadeThrow(t *testing.T) {
+	s := newUtilityService()
+	thrower := &common.Player{SteamID64: 1}
+
+	s.handleGrenadeThrow(events.GrenadeProjectileThrow{Projectile: &common.GrenadeProjectile{
+		Thrower: thrower, WeaponInstance: &common.Equipment{Type: common.EqMolotov}}})
+	// Projectiles without a weapon are ignored.
+	s.handleGrenadeThrow(events.GrenadeProjectileThrow{Projectile: &common.GrenadeProjectile{Thrower: thrower}})
+
+	utility := s.Match.Rounds[0].Utility
+	assert.Len(t, utility, 1)
+	assert.Equal(t, s.Match.Players[0], utility[0].Thrower)
+	assert.Equal(t, common.EqMolotov, utility[0].Type)
+	assert.Equal(t, 5*time.Second, utility[0].Tick)
+}
+
+func TestHandlePlayerHurtByGrenade(t *testing.T) {
+	s := newUtilityService()
+
+	s.handlePlayerHurt(events.PlayerHurt{Attacker: &common.Player{SteamID64: 1}, Player: &common.Player{SteamID64: 2},
+		Weapon: &common.Equipment{Type: common.EqHE}, HealthDamageTaken: 40})
+
+	damage := s.Match.Rounds[0].Damage
+	assert.Len(t, damage, 1)
+	assert.Equal(t, s.Match.Players[0], damage[0].Attacker)
+	assert.Equal(t, s.Match.Players[1], damage[0].Victim)
+	assert.Equal(t, common.EqHE, damage[0].Weapon)
+	assert.Equal(t, 40, damage[0].HealthDamageTaken)
+}
+
+func TestHandlePlayerFlashedIgnoresDeadPlayers(t *testing.T) {
+	s := newUtilityService()
+	entity := new(stfake.Entity)
+	entity.On("PropertyValueMust", "m_iHealth").Return(st.PropertyValue{IntVal: 0})
+	dead := &common.Player{SteamID64: 2, Entity: entity}
+
+	s.handlePlayerFlashed(events.PlayerFlashed{Attacker: &common.Player{SteamID64: 1}, Player: dead})
+
+	assert.Empty(t, s.Match.Rounds[0].Flashes)
+}
+
+func TestHandleUtilityDuringWarmup(t *testing.T) {
+	s := newUtilityService()
+	gameState := new(fake.GameState)
+	gameState.On("IsWarmupPeriod").Return(true)
+	parser := fake.NewParser()
+	parser.On("GameState").Return(gameState)
+	s.parser = parser
+
+	s.handleGrenadeThrow(events.GrenadeProjectileThrow{Projectile: &common.GrenadeProjectile{
+		Thrower: &common.Player{SteamID64: 1}, WeaponInstance: &common.Equipment{Type: common.EqFlash}}})
+
+	assert.Empty(t, s.Match.Rounds[0].Utility)
+}
@@ -1,6 +1,7 @@
 package demoparser
 
 import (
+	"github.com/golang/geo/r3"
 	"github.com/markus-wa/demoinfocs-golang/v2/pkg/demoinfocs/common"
 )
 
@@ -21,3 +22,105 @@ func GetTeamIndex(team common.Team, sidesSwitched bool) byte {
 	// Could also return an error here but we do not expect this to happen.
 	return 2
 }
+
+// Average equipment value per player from which a round is considered a full buy respectively no eco.
+const (
+	fullBuyEquipmentValue = 4000
+	ecoEquipmentValue     = 1000
+	// A team that has less money left than this per player after buying is considered to have forced.
+	forceBuyMoneyLeft = 1000
+)
+
+// ClassifyBuy returns the buy type of a team using the average equipment value and money left per player.
+// Teams below a full buy are considered to force buy when they spent nearly all their money and to half buy otherwise.
+func ClassifyBuy(equipmentValue int, money int, players int) BuyType {
+	if players == 0 {
+		return Eco
+	}
+
+	averageEquipment := equipmentValue / players
+	averageMoney := money / players
+
+	switch {
+	case averageEquipment >= fullBuyEquipmentValue:
+		return FullBuy
+	case averageEquipment < ecoEquipmentValue:
+		return Eco
+	case averageMoney < forceBuyMoneyLeft:
+		return ForceBuy
+	default:
+		return HalfBuy
+	}
+}
+
+// AlivePlayers returns all members of the team that are still alive, excluding the given player.
+// The victim of a kill event may still be reported as alive, thus it can be excluded explicitly.
+func AlivePlayers(team *common.TeamState, exclude *common.Player) []*common.Player {
+	alive := make([]*common.Player, 0)
+	if team == nil {
+		return alive
+	}
+
+	for _, member := range team.Members() {
+		if member == exclude || !member.IsAlive() {
+			continue
+		}
+
+		alive = append(alive, member)
+	}
+
+	return alive
+}
+
+// game_mode convar value of wingman matches.
+const wingmanGameMode = "2"
+
+// DetectFormat returns the format of a match using the game_mode convar, mp_maxrounds and the amount of players per team.
+// The team size is used as fallback for demos that do not contain the game_mode.
+func DetectFormat(gameMode string, maxRounds int, teamSize int) Format {
+	switch {
+	case gameMode == wingmanGameMode || gameMode == "" && teamSize > 0 && teamSize <= 2:
+		return Wingman
+	case maxRounds < defaultMaxRounds:
+		return ShortCompetitive
+	default:
+		return Competitive
+	}
+}
+
+// GetHalf returns the half, the overtime and whether it is a pistol round for the round after roundsPlayed rounds.
+// Halves and overtimes are one-based, regulation rounds are in overtime zero.
+// Only the first round of each regulation half is a pistol round as players receive more money in overtime.
+func GetHalf(roundsPlayed int, maxRounds int, overtimeMaxRounds int) (half byte, overtime byte, isPistolRound bool) {
+	regulationHalf := maxRounds / 2
+	if regulationHalf == 0 {
+		return 1, 0, roundsPlayed == 0
+	}
+
+	if roundsPlayed < maxRounds {
+		return byte(roundsPlayed/regulationHalf + 1), 0, roundsPlayed%regulationHalf == 0
+	}
+
+	overtimeRounds := roundsPlayed - maxRounds
+	overtimeHalf := overtimeMaxRounds / 2
+	if overtimeHalf == 0 {
+		return 3, 1, false
+	}
+
+	return byte(2 + overtimeRounds/overtimeHalf + 1), byte(overtimeRounds/overtimeMaxRounds + 1), false
+}
+
+// IsGun returns whether the equipment is a pistol, smg, heavy weapon or rifle.
+func IsGun(weapon common.EquipmentType) bool {
+	switch weapon.Class() {
+	case common.EqClassPistols, common.EqClassSMG, common.EqClassHeavy, common.EqClassRifle:
+		return true
+	default:
+		return false
+	}
+}
+
+// NewPosition converts a vector from the demo to a position.
+func NewPosition(v r3.Vector) Position {
+	return Position{X: v.X, Y: v.Y, Z: v.Z}
+}
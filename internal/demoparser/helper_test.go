@@ -0,0 +1,59 @@
+package demoparser_test
+
+import (
+	"testing"
+
+	"github.com/Cludch/csgo-tools/internal/demoparser"
+	"github.com/markus-wa/demoinfocs-golang/v2/pkg/demoinfocs/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyBuy(t *testing.T) {
+	assert.Equal(t, demoparser.Eco, demoparser.ClassifyBuy(4000, 10000, 5))
+	assert.Equal(t, demoparser.HalfBuy, demoparser.ClassifyBuy(10000, 12000, 5))
+	assert.Equal(t, demoparser.ForceBuy, demoparser.ClassifyBuy(15000, 1500, 5))
+	assert.Equal(t, demoparser.FullBuy, demoparser.ClassifyBuy(25000, 500, 5))
+	assert.Equal(t, demoparser.Eco, demoparser.ClassifyBuy(0, 0, 0))
+}
+
+func TestIsGun(t *testing.T) {
+	assert.True(t, demoparser.IsGun(common.EqAK47))
+	assert.True(t, demoparser.IsGun(common.EqDeagle))
+	assert.True(t, demoparser.IsGun(common.EqNova))
+	assert.False(t, demoparser.IsGun(common.EqKnife))
+	assert.False(t, demoparser.IsGun(common.EqHE))
+	assert.False(t, demoparser.IsGun(common.EqZeus))
+}
+
+func TestGetHalf(t *testing.T) {
+	tests := []struct {
+		roundsPlayed, maxRounds, overtimeMaxRounds int
+		half, overtime                             byte
+		isPistolRound                              bool
+	}{
+		{0, 30, 6, 1, 0, true},
+		{14, 30, 6, 1, 0, false},
+		{15, 30, 6, 2, 0, true},
+		{29, 30, 6, 2, 0, false},
+		{30, 30, 6, 3, 1, false},
+		{33, 30, 6, 4, 1, false},
+		{36, 30, 6, 5, 2, false},
+		{8, 16, 6, 2, 0, true},
+		{16, 16, 6, 3, 1, false},
+	}
+
+	for _, test := range tests {
+		half, overtime, isPistolRound := demoparser.GetHalf(test.roundsPlayed, test.maxRounds, test.overtimeMaxRounds)
+		assert.Equal(t, test.half, half, test)
+		assert.Equal(t, test.overtime, overtime, test)
+		assert.Equal(t, test.isPistolRound, isPistolRound, test)
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	assert.Equal(t, demoparser.Competitive, demoparser.DetectFormat("1", 30, 5))
+	assert.Equal(t, demoparser.ShortCompetitive, demoparser.DetectFormat("1", 16, 5))
+	assert.Equal(t, demoparser.Wingman, demoparser.DetectFormat("2", 16, 2))
+	assert.Equal(t, demoparser.Wingman, demoparser.DetectFormat("", 16, 2))
+	assert.Equal(t, demoparser.Competitive, demoparser.DetectFormat("", 30, 5))
+}
@@ -0,0 +1,174 @@
+package demoparser
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"math"
+	"time"
+
+	"github.com/Cludch/csgo-tools/pkg/demo"
+	"github.com/golang/geo/r3"
+	"github.com/markus-wa/demoinfocs-golang/v2/pkg/demoinfocs/common"
+	"github.com/markus-wa/demoinfocs-golang/v2/pkg/demoinfocs/events"
+	log "github.com/sirupsen/logrus"
+)
+
+// Replay holds everything required to render a round as 2D replay.
+// Positions are rounded to whole units in order to keep the files small.
+type Replay struct {
+	Map       string           `json:"map"`
+	Round     byte             `json:"round"`
+	TickRate  float64          `json:"tickRate"`
+	StartTick int              `json:"startTick"`
+	Frames    []*ReplayFrame   `json:"frames"`
+	Grenades  []*ReplayGrenade `json:"grenades"`
+}
+
+// ReplayFrame holds the state of all players at one tick.
+type ReplayFrame struct {
+	Tick    int             `json:"tick"`
+	Players []*ReplayPlayer `json:"players"`
+}
+
+// ReplayPlayer holds the state of one player at one tick.
+type ReplayPlayer struct {
+	SteamID uint64      `json:"id"`
+	Name    string      `json:"name"`
+	Team    common.Team `json:"team"`
+	X       int         `json:"x"`
+	Y       int         `json:"y"`
+	Z       int         `json:"z"`
+	ViewX   int         `json:"viewX"`
+	ViewY   int         `json:"viewY"`
+	Health  int         `json:"health"`
+	Armor   int         `json:"armor"`
+	Weapon  string      `json:"weapon,omitempty"`
+	IsAlive bool        `json:"alive"`
+}
+
+// ReplayGrenade holds the trajectory of a grenade as flattened x, y and z coordinates.
+type ReplayGrenade struct {
+	ThrowerID  uint64 `json:"throwerId"`
+	Type       string `json:"type"`
+	EndTick    int    `json:"endTick"`
+	Trajectory []int  `json:"trajectory"`
+}
+
+// Returns the amount of ticks between two replay frames or zero if replays are disabled.
+func (s *Service) replayTickInterval() int {
+	parserConfig := s.configurationService.GetConfig().Parser
+	if parserConfig == nil {
+		return 0
+	}
+
+	return parserConfig.ReplayTickInterval
+}
+
+// Returns the replay of the current round and creates it if it does not exist yet.
+func (s *Service) currentReplay() *Replay {
+	round := s.Match.Rounds[s.CurrentRound-1]
+	if round.Replay == nil {
+		tick := s.parser.GameState().IngameTick()
+		round.Replay = &Replay{Map: s.Match.Map, Round: s.CurrentRound, TickRate: s.parser.TickRate(), StartTick: tick}
+	}
+
+	return round.Replay
+}
+
+// Samples the state of all players every configured amount of ticks while the round is ongoing.
+func (s *Service) handleReplayFrame(e events.FrameDone) {
+	if s.parser.GameState().IsWarmupPeriod() || !s.RoundOngoing {
+		return
+	}
+
+	replay := s.currentReplay()
+	tick := s.parser.GameState().IngameTick()
+	if len(replay.Frames) > 0 && tick-replay.Frames[len(replay.Frames)-1].Tick < s.replayTickInterval() {
+		return
+	}
+
+	frame := &ReplayFrame{Tick: tick}
+	for _, p := range s.parser.GameState().Participants().Playing() {
+		position := p.Position()
+		replayPlayer := &ReplayPlayer{SteamID: p.SteamID64, Name: p.Name, Team: p.Team,
+			X: round(position.X), Y: round(position.Y), Z: round(position.Z),
+			ViewX: round(float64(p.ViewDirectionX())), ViewY: round(float64(p.ViewDirectionY())),
+			Health: p.Health(), Armor: p.Armor(), IsAlive: p.IsAlive()}
+
+		if weapon := p.ActiveWeapon(); weapon != nil {
+			replayPlayer.Weapon = weapon.String()
+		}
+
+		frame.Players = append(frame.Players, replayPlayer)
+	}
+
+	replay.Frames = append(replay.Frames, frame)
+}
+
+// Adds the full trajectory of a grenade to the replay once it got destroyed.
+func (s *Service) handleGrenadeDestroy(e events.GrenadeProjectileDestroy) {
+	if s.parser.GameState().IsWarmupPeriod() || !s.RoundOngoing || e.Projectile == nil {
+		return
+	}
+
+	grenade := &ReplayGrenade{EndTick: s.parser.GameState().IngameTick(), Trajectory: flatten(e.Projectile.Trajectory)}
+
+	if e.Projectile.Thrower != nil {
+		grenade.ThrowerID = e.Projectile.Thrower.SteamID64
+	}
+
+	if e.Projectile.WeaponInstance != nil {
+		grenade.Type = e.Projectile.WeaponInstance.String()
+	}
+
+	replay := s.currentReplay()
+	replay.Grenades = append(replay.Grenades, grenade)
+}
+
+// WriteReplays saves one gzipped JSON replay file per round next to the demo file in the store.
+func (s *Service) WriteReplays(ctx context.Context, store demo.DemoStore, demoFilename string) error {
+	for _, round := range s.Match.Rounds {
+		if round.Replay == nil {
+			continue
+		}
+
+		filename := demo.ReplayFilename(demoFilename, round.Replay.Round)
+		if err := writeReplay(ctx, store, filename, round.Replay); err != nil {
+			return err
+		}
+
+		const msg = "demoparser: wrote replay %s"
+		log.Debugf(msg, filename)
+	}
+
+	return nil
+}
+
+func writeReplay(ctx context.Context, store demo.DemoStore, filename string, replay *Replay) error {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(w).Encode(replay); err != nil {
+		return err
+	}
+
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return store.Save(ctx, filename, &buf, time.Now())
+}
+
+func flatten(vectors []r3.Vector) []int {
+	flat := make([]int, 0, len(vectors)*3)
+	for _, v := range vectors {
+		flat = append(flat, round(v.X), round(v.Y), round(v.Z))
+	}
+
+	return flat
+}
+
+func round(value float64) int {
+	return int(math.Round(value))
+}
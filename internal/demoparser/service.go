@@ -1,9 +1,9 @@
 package demoparser
 
 import (
+	"context"
 	"errors"
-	"os"
-	"path"
+	"io"
 	"strconv"
 	"time"
 
@@ -29,6 +29,7 @@ type Service struct {
 	RoundOngoing         bool
 	SidesSwitched        bool
 	GameOver             bool
+	lastPositionSample   time.Duration
 }
 
 func NewService(c config.UseCase) *Service {
@@ -39,16 +40,28 @@ func NewService(c config.UseCase) *Service {
 
 // MatchData holds information about the match itself.
 type MatchData struct {
-	ID       entity.ID
-	Map      string
-	Header   *common.DemoHeader
-	Players  []*Player
-	Teams    [2]*Team
-	Duration time.Duration
-	Time     time.Time
-	Rounds   []*Round
+	ID                entity.ID
+	Map               string
+	Format            Format
+	MaxRounds         int
+	OvertimeMaxRounds int
+	Header            *common.DemoHeader
+	Players           []*Player
+	Teams             [2]*Team
+	Duration          time.Duration
+	Time              time.Time
+	Rounds            []*Round
 }
 
+// Format describes the game mode and length of a match.
+type Format string
+
+const (
+	Competitive      Format = "Competitive"
+	ShortCompetitive Format = "ShortCompetitive"
+	Wingman          Format = "Wingman"
+)
+
 // Team represents a team and links to it's players.
 type Team struct {
 	StartedAs common.Team
@@ -68,12 +81,78 @@ type Player struct {
 
 // Round contains information about one round.
 type Round struct {
-	Duration  time.Duration
-	Kills     []*Kill
-	Damage    []*Damage
-	Winner    *Team
-	WinReason events.RoundEndReason
-	MVP       *Player
+	StartTick         time.Duration
+	FreezetimeEndTick time.Duration
+	Duration          time.Duration
+	// Half is the one-based number of the half including the overtime halves.
+	Half byte
+	// Overtime is the one-based number of the overtime or zero for rounds in regulation.
+	Overtime      byte
+	IsPistolRound bool
+	Kills         []*Kill
+	Damage        []*Damage
+	Shots         []*Shot
+	Clutches      []*Clutch
+	Economy       []*Economy
+	Teams         []*TeamEconomy
+	Utility       []*Utility
+	Flashes       []*Flash
+	Positions     []*PositionSample
+	Replay        *Replay
+	Bomb          *Bomb
+	Winner        *Team
+	WinnerSide    common.Team
+	WinReason     events.RoundEndReason
+	MVP           *Player
+}
+
+// Bomb holds information about the bomb plant and defuse during a round.
+type Bomb struct {
+	Site            string
+	Planter         *Player
+	PlantStartTick  time.Duration
+	PlantTick       time.Duration
+	Defuser         *Player
+	DefuseStartTick time.Duration
+	DefuseTick      time.Duration
+	HasKit          bool
+	IsPlanted       bool
+	IsDefused       bool
+	IsExploded      bool
+}
+
+// Clutch describes a situation in which a player is the last one alive on his side.
+type Clutch struct {
+	Player    *Player
+	Opponents byte
+	Won       bool
+}
+
+// BuyType classifies the equipment a team bought for a round.
+type BuyType string
+
+const (
+	Eco      BuyType = "Eco"
+	HalfBuy  BuyType = "HalfBuy"
+	ForceBuy BuyType = "ForceBuy"
+	FullBuy  BuyType = "FullBuy"
+)
+
+// Economy holds the equipment and money of a player at the end of the freeze time.
+type Economy struct {
+	Player         *Player
+	EquipmentValue int
+	Money          int
+	MoneySpent     int
+}
+
+// TeamEconomy holds the summed up equipment and money of a team at the end of the freeze time.
+type TeamEconomy struct {
+	Team           *Team
+	EquipmentValue int
+	Money          int
+	MoneySpent     int
+	BuyType        BuyType
 }
 
 // Kill holds information about a kill that happenend during the match.
@@ -90,29 +169,79 @@ type Kill struct {
 	IsNoScope       bool
 	IsThroughSmoke  bool
 	IsThroughWall   bool
+	KillerPosition  Position
+	VictimPosition  Position
+	KillerSide      common.Team
+	VictimSide      common.Team
 }
 
 type Damage struct {
+	Tick              time.Duration
 	Attacker          *Player
+	Victim            *Player
+	Weapon            common.EquipmentType
+	HitGroup          events.HitGroup
 	HealthDamageTaken int
+	ArmorDamageTaken  int
 }
 
-// Parse takes a demo file and starts parsing by registering all required event handlers.
-func (s *Service) Parse(dir string, demoFile *demo.Demo) error {
-	s.Match = &MatchData{ID: demoFile.ID, Time: demoFile.MatchTime}
+// Shot holds information about a bullet fired by a player.
+type Shot struct {
+	Tick    time.Duration
+	Shooter *Player
+	Weapon  common.EquipmentType
+}
+
+// Position describes a point on the map in world coordinates.
+type Position struct {
+	X float64
+	Y float64
+	Z float64
+}
 
-	f, err := os.Open(path.Join(dir, demoFile.Filename))
+// PositionSample holds the position of a player at a point in time.
+type PositionSample struct {
+	Tick     time.Duration
+	Player   *Player
+	Position Position
+}
+
+// Utility holds information about a thrown grenade.
+type Utility struct {
+	Tick    time.Duration
+	Thrower *Player
+	Type    common.EquipmentType
+}
 
+// Flash holds information about a player being blinded by a flashbang.
+type Flash struct {
+	Tick     time.Duration
+	Attacker *Player
+	Victim   *Player
+	Duration time.Duration
+}
+
+// Parse takes a demo file from the store, which may be compressed, and starts parsing by registering all required event handlers.
+func (s *Service) Parse(ctx context.Context, store demo.DemoStore, demoFile *demo.Demo) error {
+	f, err := demo.OpenDemo(ctx, store, demoFile.Filename)
 	if err != nil {
 		return err
 	}
+	defer f.Close()
+
+	return s.ParseReader(ctx, f, demoFile)
+}
+
+// ParseReader parses the uncompressed demo from the reader. The demo file holds the meta information of the match.
+// Parsing stops and the error of the context is returned if the context gets cancelled.
+func (s *Service) ParseReader(ctx context.Context, r io.Reader, demoFile *demo.Demo) error {
+	s.Match = &MatchData{ID: demoFile.ID, Time: demoFile.MatchTime}
 
 	const msg = "Starting demo parsing of match %s (file %s)"
 	log.Infof(msg, s.Match.ID, demoFile.Filename)
 
-	s.parser = demoinfocs.NewParser(f)
+	s.parser = demoinfocs.NewParser(r)
 	defer s.parser.Close()
-	defer f.Close()
 
 	// Parsing the header within an event handler crashes.
 	header, _ := s.parser.ParseHeader()
@@ -123,13 +252,48 @@ func (s *Service) Parse(dir string, demoFile *demo.Demo) error {
 	s.parser.RegisterEventHandler(s.handleGamePhaseChanged)
 	s.parser.RegisterEventHandler(s.handleKill)
 	s.parser.RegisterEventHandler(s.handlePlayerHurt)
+	s.parser.RegisterEventHandler(s.handleWeaponFire)
+	s.parser.RegisterEventHandler(s.handleGrenadeThrow)
+	s.parser.RegisterEventHandler(s.handlePlayerFlashed)
+	s.parser.RegisterEventHandler(s.handleBombPlantBegin)
+	s.parser.RegisterEventHandler(s.handleBombPlanted)
+	s.parser.RegisterEventHandler(s.handleBombDefuseStart)
+	s.parser.RegisterEventHandler(s.handleBombDefused)
+	s.parser.RegisterEventHandler(s.handleBombExplode)
 	s.parser.RegisterEventHandler(s.handleMVP)
 	s.parser.RegisterEventHandler(s.handleRoundStart)
+	s.parser.RegisterEventHandler(s.handleFreezetimeEnd)
 	s.parser.RegisterEventHandler(s.handleRoundEnd)
 	s.parser.RegisterEventHandler(s.handleRankUpdate)
 	s.parser.RegisterEventHandler(s.handleParserWarn)
 
-	return s.parser.ParseToEnd()
+	if s.positionSampleInterval() > 0 {
+		s.parser.RegisterEventHandler(s.handleFrameDone)
+	}
+
+	if s.replayTickInterval() > 0 {
+		s.parser.RegisterEventHandler(s.handleReplayFrame)
+		s.parser.RegisterEventHandler(s.handleGrenadeDestroy)
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			s.parser.Cancel()
+		case <-done:
+		}
+	}()
+
+	if err := s.parser.ParseToEnd(); err != nil {
+		if errors.Is(err, demoinfocs.ErrCancelled) && ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return err
+	}
+
+	return nil
 }
 
 // AddPlayer adds a player to the game and returns the pointer.
@@ -166,6 +330,55 @@ func (s *Service) getPlayer(player *common.Player) (*Player, error) {
 	return nil, errors.New("Player not found in local match struct " + strconv.FormatUint(player.SteamID64, 10))
 }
 
+// Default values of the convars that decide about the length of a match.
+const (
+	defaultMaxRounds         = 30
+	defaultOvertimeMaxRounds = 6
+)
+
+// Returns the maximum amount of rounds in regulation as set by mp_maxrounds.
+func (s *Service) maxRounds() int {
+	return s.intConVar("mp_maxrounds", defaultMaxRounds)
+}
+
+// Returns the maximum amount of rounds of one overtime as set by mp_overtime_maxrounds.
+func (s *Service) overtimeMaxRounds() int {
+	return s.intConVar("mp_overtime_maxrounds", defaultOvertimeMaxRounds)
+}
+
+// Detects the format of the match from the convars and the size of the teams.
+func (s *Service) detectFormat() {
+	gameState := s.parser.GameState()
+	teamSize := len(gameState.TeamTerrorists().Members())
+	if ctSize := len(gameState.TeamCounterTerrorists().Members()); ctSize > teamSize {
+		teamSize = ctSize
+	}
+
+	s.Match.MaxRounds = s.maxRounds()
+	s.Match.OvertimeMaxRounds = s.overtimeMaxRounds()
+	s.Match.Format = DetectFormat(gameState.Rules().ConVars()["game_mode"], s.Match.MaxRounds, teamSize)
+}
+
+// Returns the convar as positive int or the fallback if it is not set.
+func (s *Service) intConVar(name string, fallback int) int {
+	value, err := strconv.Atoi(s.parser.GameState().Rules().ConVars()[name])
+	if err != nil || value <= 0 {
+		return fallback
+	}
+
+	return value
+}
+
+// Returns the interval in which player positions are sampled or zero if sampling is disabled.
+func (s *Service) positionSampleInterval() time.Duration {
+	parserConfig := s.configurationService.GetConfig().Parser
+	if parserConfig == nil {
+		return 0
+	}
+
+	return time.Duration(parserConfig.PositionSampleInterval) * time.Millisecond
+}
+
 func (s *Service) debug(message string) {
 	if s.configurationService.IsTrace() {
 		log.WithFields(log.Fields{
@@ -0,0 +1,105 @@
+package demostore
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Cludch/csgo-tools/pkg/demo"
+	log "github.com/sirupsen/logrus"
+)
+
+// Local stores demos in a directory on the local disk.
+type Local struct {
+	dir string
+}
+
+// NewLocal creates a store for the demos in dir.
+func NewLocal(dir string) *Local {
+	return &Local{
+		dir: dir,
+	}
+}
+
+// Save writes the file to a temporary file first and renames it afterwards, thus incomplete files are never visible.
+func (s *Local) Save(ctx context.Context, filename string, r io.Reader, modTime time.Time) error {
+	f, err := os.CreateTemp(s.dir, filepath.Base(filename)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	if _, err := io.Copy(f, r); err != nil {
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Chtimes(f.Name(), modTime, modTime); err != nil {
+		const msg = "demostore: unable to set the modification time of %s: %s"
+		log.Warnf(msg, filename, err)
+	}
+
+	return os.Rename(f.Name(), s.path(filename))
+}
+
+// Open opens the file as it is stored.
+func (s *Local) Open(ctx context.Context, filename string) (io.ReadCloser, error) {
+	return os.Open(s.path(filename))
+}
+
+// Stat returns the size and modification time of the file.
+func (s *Local) Stat(ctx context.Context, filename string) (*demo.FileInfo, error) {
+	stats, err := os.Stat(s.path(filename))
+	if err != nil {
+		return nil, err
+	}
+
+	return &demo.FileInfo{Filename: filepath.Base(filename), Size: stats.Size(), ModTime: stats.ModTime()}, nil
+}
+
+// Delete deletes the file. Deleting a file that does not exist is no error.
+func (s *Local) Delete(ctx context.Context, filename string) error {
+	if err := os.Remove(s.path(filename)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	const msg = "demostore: deleted %s"
+	log.Debugf(msg, filename)
+
+	return nil
+}
+
+// List returns all files in the directory.
+func (s *Local) List(ctx context.Context) ([]*demo.FileInfo, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]*demo.FileInfo, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		files = append(files, &demo.FileInfo{Filename: entry.Name(), Size: info.Size(), ModTime: info.ModTime()})
+	}
+
+	return files, nil
+}
+
+func (s *Local) path(filename string) string {
+	return filepath.Join(s.dir, filepath.Base(filename))
+}
@@ -0,0 +1,76 @@
+package demostore_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Cludch/csgo-tools/internal/demostore"
+	"github.com/Cludch/csgo-tools/pkg/demo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocal(t *testing.T) {
+	dir := t.TempDir()
+	store := demostore.NewLocal(dir)
+	ctx := context.Background()
+	modTime := time.Date(2021, 11, 20, 18, 0, 0, 0, time.UTC)
+
+	assert.Nil(t, store.Save(ctx, "match.dem", bytes.NewReader([]byte("HL2DEMO\x00")), modTime))
+
+	info, err := store.Stat(ctx, "match.dem")
+	assert.Nil(t, err)
+	assert.Equal(t, "match.dem", info.Filename)
+	assert.Equal(t, int64(8), info.Size)
+	assert.True(t, modTime.Equal(info.ModTime))
+
+	// No temporary files are left behind.
+	files, err := store.List(ctx)
+	assert.Nil(t, err)
+	assert.Len(t, files, 1)
+
+	_, err = store.Stat(ctx, "missing.dem")
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+}
+
+func TestArchive(t *testing.T) {
+	dir := t.TempDir()
+	content := []byte("HL2DEMO\x00demo content")
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "match.dem"), content, 0644))
+
+	store := demostore.NewLocal(dir)
+	ctx := context.Background()
+	filename, err := demo.Archive(ctx, store, "match.dem")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "match.dem.zst", filename)
+	assert.False(t, demo.Exists(ctx, store, "match.dem"))
+	assert.True(t, demo.Exists(ctx, store, "match.dem.zst"))
+
+	r, err := demo.OpenDemo(ctx, store, filename)
+	assert.Nil(t, err)
+	defer r.Close()
+
+	decompressed, err := io.ReadAll(r)
+	assert.Nil(t, err)
+	assert.Equal(t, content, decompressed)
+
+	// Archived demos are not compressed twice.
+	filename, err = demo.Archive(ctx, store, filename)
+	assert.Nil(t, err)
+	assert.Equal(t, "match.dem.zst", filename)
+
+	demos, err := demo.Scan(ctx, store)
+	assert.Nil(t, err)
+	assert.Len(t, demos, 1)
+	assert.Equal(t, "match.dem.zst", demos[0].Filename)
+
+	assert.Nil(t, store.Delete(ctx, filename))
+	assert.False(t, demo.Exists(ctx, store, filename))
+	assert.Nil(t, store.Delete(ctx, filename))
+}
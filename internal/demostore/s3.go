@@ -0,0 +1,154 @@
+package demostore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/Cludch/csgo-tools/pkg/demo"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	log "github.com/sirupsen/logrus"
+)
+
+// Metadata key holding the modification time of the demo, as objects only know when they were uploaded.
+const modTimeMetadata = "Mod-Time"
+
+// Size of the parts demos are uploaded in. Without it the client buffers a part of 512 MiB for uploads of unknown size.
+const partSize = 16 * 1024 * 1024
+
+// S3 stores demos in a bucket of an S3-compatible object store like AWS S3 or MinIO.
+type S3 struct {
+	client *minio.Client
+	bucket string
+	prefix string
+}
+
+// NewS3 creates a store for the demos in the bucket. All objects are stored below the optional prefix.
+func NewS3(endpoint string, accessKey string, secretKey string, region string, useSSL bool, bucket string, prefix string) (*S3, error) {
+	client, err := minio.New(endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(accessKey, secretKey, ""),
+		Secure: useSSL,
+		Region: region,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &S3{
+		client: client,
+		bucket: bucket,
+		prefix: strings.Trim(prefix, "/"),
+	}, nil
+}
+
+// Save uploads the file. Objects only become visible once the upload is complete.
+func (s *S3) Save(ctx context.Context, filename string, r io.Reader, modTime time.Time) error {
+	opts := minio.PutObjectOptions{
+		ContentType:  "application/octet-stream",
+		PartSize:     partSize,
+		UserMetadata: map[string]string{modTimeMetadata: modTime.UTC().Format(time.RFC3339)},
+	}
+
+	_, err := s.client.PutObject(ctx, s.bucket, s.key(filename), r, -1, opts)
+	return err
+}
+
+// Open downloads the file as it is stored.
+func (s *S3) Open(ctx context.Context, filename string) (io.ReadCloser, error) {
+	// The object is only requested on the first read, thus check whether it exists first.
+	if _, err := s.Stat(ctx, filename); err != nil {
+		return nil, err
+	}
+
+	return s.client.GetObject(ctx, s.bucket, s.key(filename), minio.GetObjectOptions{})
+}
+
+// Stat returns the size and modification time of the file.
+func (s *S3) Stat(ctx context.Context, filename string) (*demo.FileInfo, error) {
+	object, err := s.client.StatObject(ctx, s.bucket, s.key(filename), minio.StatObjectOptions{})
+	if err != nil {
+		return nil, handleError(filename, err)
+	}
+
+	return s.fileInfo(object), nil
+}
+
+// Delete deletes the file. Deleting a file that does not exist is no error.
+func (s *S3) Delete(ctx context.Context, filename string) error {
+	if err := s.client.RemoveObject(ctx, s.bucket, s.key(filename), minio.RemoveObjectOptions{}); err != nil {
+		return handleError(filename, err)
+	}
+
+	const msg = "demostore: deleted %s"
+	log.Debugf(msg, filename)
+
+	return nil
+}
+
+// List returns all files below the prefix.
+func (s *S3) List(ctx context.Context) ([]*demo.FileInfo, error) {
+	files := make([]*demo.FileInfo, 0)
+
+	opts := minio.ListObjectsOptions{Prefix: s.listPrefix(), WithMetadata: true}
+	for object := range s.client.ListObjects(ctx, s.bucket, opts) {
+		if object.Err != nil {
+			return nil, object.Err
+		}
+
+		// Skip the common prefixes of nested objects.
+		if strings.HasSuffix(object.Key, "/") {
+			continue
+		}
+
+		files = append(files, s.fileInfo(object))
+	}
+
+	return files, nil
+}
+
+func (s *S3) fileInfo(object minio.ObjectInfo) *demo.FileInfo {
+	info := &demo.FileInfo{Filename: path.Base(object.Key), Size: object.Size, ModTime: object.LastModified}
+
+	// Listings return the metadata with its header name.
+	value, ok := object.UserMetadata[modTimeMetadata]
+	if !ok {
+		value = object.UserMetadata["X-Amz-Meta-"+modTimeMetadata]
+	}
+
+	if modTime, err := time.Parse(time.RFC3339, value); err == nil {
+		info.ModTime = modTime
+	}
+
+	return info
+}
+
+func (s *S3) key(filename string) string {
+	if s.prefix == "" {
+		return path.Base(filename)
+	}
+
+	return s.prefix + "/" + path.Base(filename)
+}
+
+// Returns the prefix of all keys. Without a configured prefix the whole bucket is listed.
+func (s *S3) listPrefix() string {
+	if s.prefix == "" {
+		return ""
+	}
+
+	return s.prefix + "/"
+}
+
+// Maps missing objects to os.ErrNotExist.
+func handleError(filename string, err error) error {
+	if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+		return fmt.Errorf("demostore: %s: %w", filename, os.ErrNotExist)
+	}
+
+	return err
+}
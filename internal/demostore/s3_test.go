@@ -0,0 +1,272 @@
+package demostore_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Cludch/csgo-tools/internal/demostore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestS3(t *testing.T) {
+	for _, prefix := range []string{"", "demos"} {
+		t.Run(fmt.Sprintf("prefix %q", prefix), func(t *testing.T) {
+			fake := newFakeS3()
+			fake.put("unrelated.dem", []byte("HL2DEMO\x00"))
+			fake.put("other/unrelated.dem", []byte("HL2DEMO\x00"))
+
+			server := httptest.NewServer(fake)
+			defer server.Close()
+
+			endpoint := strings.TrimPrefix(server.URL, "http://")
+			store, err := demostore.NewS3(endpoint, "access", "secret", "us-east-1", false, "bucket", prefix)
+			assert.Nil(t, err)
+
+			ctx := context.Background()
+			content := []byte("HL2DEMO\x00demo content")
+			modTime := time.Date(2021, 11, 20, 18, 0, 0, 0, time.UTC)
+
+			assert.Nil(t, store.Save(ctx, "match.dem", bytes.NewReader(content), modTime))
+
+			info, err := store.Stat(ctx, "match.dem")
+			assert.Nil(t, err)
+			assert.Equal(t, "match.dem", info.Filename)
+			assert.Equal(t, int64(len(content)), info.Size)
+			assert.True(t, modTime.Equal(info.ModTime))
+
+			r, err := store.Open(ctx, "match.dem")
+			assert.Nil(t, err)
+			stored, err := io.ReadAll(r)
+			assert.Nil(t, err)
+			assert.Nil(t, r.Close())
+			assert.Equal(t, content, stored)
+
+			// Only the demos directly below the prefix are listed.
+			files, err := store.List(ctx)
+			assert.Nil(t, err)
+			filenames := make([]string, 0)
+			for _, f := range files {
+				filenames = append(filenames, f.Filename)
+				if f.Filename == "match.dem" {
+					assert.True(t, modTime.Equal(f.ModTime))
+				}
+			}
+
+			if prefix == "" {
+				assert.ElementsMatch(t, []string{"match.dem", "unrelated.dem"}, filenames)
+			} else {
+				assert.Equal(t, []string{"match.dem"}, filenames)
+			}
+
+			assert.Nil(t, store.Delete(ctx, "match.dem"))
+			assert.Nil(t, store.Delete(ctx, "match.dem"))
+
+			_, err = store.Stat(ctx, "match.dem")
+			assert.True(t, errors.Is(err, os.ErrNotExist))
+
+			_, err = store.Open(ctx, "match.dem")
+			assert.True(t, errors.Is(err, os.ErrNotExist))
+		})
+	}
+}
+
+// In-memory stand-in for MinIO serving the parts of the S3 API used by the store. It serves a single bucket.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string]*fakeObject
+	uploads map[string]*fakeUpload
+	nextID  int
+}
+
+type fakeObject struct {
+	data     []byte
+	metadata map[string]string
+	modified time.Time
+}
+
+type fakeUpload struct {
+	key      string
+	metadata map[string]string
+	parts    map[int][]byte
+}
+
+func newFakeS3() *fakeS3 {
+	return &fakeS3{objects: make(map[string]*fakeObject), uploads: make(map[string]*fakeUpload)}
+}
+
+func (f *fakeS3) put(key string, data []byte) {
+	f.objects[key] = &fakeObject{data: data, metadata: map[string]string{}, modified: time.Now().UTC()}
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// Requests use the path style: /bucket/key
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)
+	key := ""
+	if len(parts) == 2 {
+		key = parts[1]
+	}
+	query := r.URL.Query()
+
+	switch {
+	case key == "" && r.Method == http.MethodGet:
+		f.list(w, query.Get("prefix"), query.Get("delimiter"))
+	case r.Method == http.MethodPost && has(query, "uploads"):
+		f.nextID++
+		id := strconv.Itoa(f.nextID)
+		f.uploads[id] = &fakeUpload{key: key, metadata: userMetadata(r.Header), parts: make(map[int][]byte)}
+		writeXML(w, struct {
+			XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+			Bucket   string
+			Key      string
+			UploadId string
+		}{Bucket: parts[0], Key: key, UploadId: id})
+	case r.Method == http.MethodPut && has(query, "uploadId"):
+		upload := f.uploads[query.Get("uploadId")]
+		n, _ := strconv.Atoi(query.Get("partNumber"))
+		upload.parts[n], _ = io.ReadAll(r.Body)
+		w.Header().Set("ETag", fmt.Sprintf(`"part%d"`, n))
+	case r.Method == http.MethodPost && has(query, "uploadId"):
+		upload := f.uploads[query.Get("uploadId")]
+		delete(f.uploads, query.Get("uploadId"))
+
+		numbers := make([]int, 0, len(upload.parts))
+		for n := range upload.parts {
+			numbers = append(numbers, n)
+		}
+		sort.Ints(numbers)
+
+		var data []byte
+		for _, n := range numbers {
+			data = append(data, upload.parts[n]...)
+		}
+		f.objects[upload.key] = &fakeObject{data: data, metadata: upload.metadata, modified: time.Now().UTC()}
+
+		writeXML(w, struct {
+			XMLName xml.Name `xml:"CompleteMultipartUploadResult"`
+			Bucket  string
+			Key     string
+			ETag    string
+		}{Bucket: parts[0], Key: upload.key, ETag: `"object"`})
+	case r.Method == http.MethodDelete && has(query, "uploadId"):
+		delete(f.uploads, query.Get("uploadId"))
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodHead || r.Method == http.MethodGet:
+		object, ok := f.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			if r.Method == http.MethodGet {
+				fmt.Fprint(w, "<Error><Code>NoSuchKey</Code></Error>")
+			}
+			return
+		}
+
+		w.Header().Set("Content-Length", strconv.Itoa(len(object.data)))
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Last-Modified", object.modified.Format(http.TimeFormat))
+		w.Header().Set("ETag", `"object"`)
+		for k, v := range object.metadata {
+			w.Header().Set("X-Amz-Meta-"+k, v)
+		}
+
+		if r.Method == http.MethodGet {
+			w.Write(object.data)
+		}
+	case r.Method == http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+// Lists the objects like ListObjectsV2 including the user metadata like MinIO does.
+func (f *fakeS3) list(w http.ResponseWriter, prefix string, delimiter string) {
+	type content struct {
+		Key          string
+		LastModified time.Time
+		ETag         string
+		Size         int64
+		UserMetadata struct {
+			ModTime string `xml:"X-Amz-Meta-Mod-Time,omitempty"`
+		}
+	}
+	type commonPrefix struct {
+		Prefix string
+	}
+
+	result := struct {
+		XMLName        xml.Name `xml:"ListBucketResult"`
+		Prefix         string
+		EncodingType   string
+		Contents       []content
+		CommonPrefixes []commonPrefix
+	}{Prefix: prefix, EncodingType: "url"}
+
+	keys := make([]string, 0, len(f.objects))
+	for key := range f.objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	seen := make(map[string]bool)
+	for _, key := range keys {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+
+		rest := strings.TrimPrefix(key, prefix)
+		if i := strings.Index(rest, delimiter); delimiter != "" && i >= 0 {
+			if p := prefix + rest[:i+1]; !seen[p] {
+				seen[p] = true
+				result.CommonPrefixes = append(result.CommonPrefixes, commonPrefix{Prefix: url.QueryEscape(p)})
+			}
+			continue
+		}
+
+		object := f.objects[key]
+		c := content{Key: url.QueryEscape(key), LastModified: object.modified, ETag: `"object"`, Size: int64(len(object.data))}
+		c.UserMetadata.ModTime = object.metadata["Mod-Time"]
+		result.Contents = append(result.Contents, c)
+	}
+
+	writeXML(w, result)
+}
+
+// Returns the x-amz-meta-* headers without their prefix.
+func userMetadata(header http.Header) map[string]string {
+	metadata := make(map[string]string)
+	for k := range header {
+		if strings.HasPrefix(k, "X-Amz-Meta-") {
+			metadata[strings.TrimPrefix(k, "X-Amz-Meta-")] = header.Get(k)
+		}
+	}
+
+	return metadata
+}
+
+func writeXML(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/xml")
+	xml.NewEncoder(w).Encode(v)
+}
+
+func has(query url.Values, key string) bool {
+	_, ok := query[key]
+	return ok
+}
@@ -0,0 +1,23 @@
+package demostore
+
+import (
+	"github.com/Cludch/csgo-tools/internal/config"
+	"github.com/Cludch/csgo-tools/pkg/demo"
+)
+
+// Backends of the demo store.
+const (
+	BackendLocal = "local"
+	BackendS3    = "s3"
+)
+
+// New creates the demo store configured in the storage config.
+// Demos are stored in the demos dir if no other backend is configured.
+func New(c *config.Config) (demo.DemoStore, error) {
+	if c.Storage == nil || c.Storage.Backend != BackendS3 || c.Storage.S3 == nil {
+		return NewLocal(c.DemosDir), nil
+	}
+
+	s3Config := c.Storage.S3
+	return NewS3(s3Config.Endpoint, s3Config.AccessKey, s3Config.SecretKey, s3Config.Region, s3Config.UseSSL, s3Config.Bucket, s3Config.Prefix)
+}
@@ -3,7 +3,6 @@ package entity
 import (
 	"context"
 	"fmt"
-	"log"
 
 	"github.com/Cludch/csgo-tools/internal/config"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -12,33 +11,40 @@ import (
 
 type Service struct {
 	configurationService config.UseCase
-	ctx                  context.Context
 	client               *mongo.Client
 }
 
-func NewService(c config.UseCase) *Service {
+// NewService connects to the database. An error is returned if the database is not reachable.
+func NewService(ctx context.Context, c config.UseCase) (*Service, error) {
 	s := &Service{
 		configurationService: c,
 	}
-	s.connect()
-	return s
+
+	if err := s.connect(ctx); err != nil {
+		return nil, err
+	}
+
+	return s, nil
 }
 
-func (s *Service) connect() {
+func (s *Service) connect(ctx context.Context) error {
 	dbConfig := s.configurationService.GetConfig().Database
 	const connString = "mongodb://%v:%v@%v:%v/%v"
 	dsn := fmt.Sprintf(connString,
 		dbConfig.Username, dbConfig.Password, dbConfig.Host, dbConfig.Port, dbConfig.Database)
 	clientOptions := options.Client().ApplyURI(dsn)
-	mongoClient, err := mongo.Connect(s.ctx, clientOptions)
+	mongoClient, err := mongo.Connect(ctx, clientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	s.client = mongoClient
 
-	if err = s.client.Ping(s.ctx, nil); err != nil {
-		log.Fatal(err)
-	}
+	return s.client.Ping(ctx, nil)
+}
+
+// Disconnect closes all connections to the database.
+func (s *Service) Disconnect(ctx context.Context) error {
+	return s.client.Disconnect(ctx)
 }
 
 func (s *Service) GetCollection(collection string) *mongo.Collection {
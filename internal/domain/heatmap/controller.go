@@ -0,0 +1,117 @@
+package heatmap
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Cludch/csgo-tools/internal/domain/entity"
+	"github.com/Cludch/csgo-tools/pkg/radar"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+type Controller struct {
+	service  UseCase
+	radarDir string
+}
+
+// NewController creates a controller which draws the heatmaps on top of the radar images found in radarDir.
+func NewController(s UseCase, radarDir string) *Controller {
+	return &Controller{
+		service:  s,
+		radarDir: radarDir,
+	}
+}
+
+// GetMatchHeatmap renders the positions of all players or the one given by the player query of one match.
+func (c *Controller) GetMatchHeatmap(g *gin.Context) {
+	id, err := entity.StringToID(g.Param("id"))
+	if err != nil {
+		g.JSON(http.StatusBadRequest, gin.H{"error": "Invalid match id!"})
+		return
+	}
+
+	h, err := c.service.GetHeatmap(g.Request.Context(), id)
+	if err != nil {
+		handleServiceError(g, err)
+		return
+	}
+
+	steamID, _ := strconv.ParseUint(g.Query("player"), 10, 64)
+	c.render(g, h.Map, []*Heatmap{h}, steamID)
+}
+
+// GetPlayerHeatmap renders the positions of one player across all matches on a map.
+func (c *Controller) GetPlayerHeatmap(g *gin.Context) {
+	steamID, err := strconv.ParseUint(g.Param("id"), 10, 64)
+	if err != nil {
+		g.JSON(http.StatusBadRequest, gin.H{"error": "Invalid player id!"})
+		return
+	}
+
+	mapName := g.Param("map")
+	heatmaps, err := c.service.GetPlayerHeatmaps(g.Request.Context(), steamID, mapName)
+	if err != nil {
+		handleServiceError(g, err)
+		return
+	}
+
+	c.render(g, mapName, heatmaps, steamID)
+}
+
+// Draws the positions of the requested type on the radar image. All players are drawn if steamID is 0.
+func (c *Controller) render(g *gin.Context, mapName string, heatmaps []*Heatmap, steamID uint64) {
+	overview, err := radar.GetOverview(mapName)
+	if err != nil {
+		g.JSON(http.StatusNotFound, gin.H{"error": "No overview for this map!"})
+		return
+	}
+
+	t := Type(g.DefaultQuery("type", string(Deaths)))
+	if t != Kills && t != Deaths && t != Positions {
+		g.JSON(http.StatusBadRequest, gin.H{"error": "Invalid heatmap type!"})
+		return
+	}
+
+	var points []radar.Point
+	for _, h := range heatmaps {
+		for _, p := range h.Players {
+			if steamID != 0 && p.SteamID != steamID {
+				continue
+			}
+
+			positions := p.GetPositions(t)
+			for i := 0; i+1 < len(positions); i += 2 {
+				x, y := overview.Translate(float64(positions[i]), float64(positions[i+1]))
+				points = append(points, radar.Point{X: x, Y: y})
+			}
+		}
+	}
+
+	// Fall back to a plain background if the radar image is not available.
+	background, err := radar.LoadImage(c.radarDir, mapName)
+	if err != nil {
+		const msg = "heatmap: no radar image for %s: %s"
+		log.Debugf(msg, mapName, err)
+	}
+
+	var buf bytes.Buffer
+	if err := radar.RenderHeatmap(&buf, background, radar.RadarSize, points); err != nil {
+		log.Error(err)
+		g.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error!"})
+		return
+	}
+
+	g.Data(http.StatusOK, "image/png", buf.Bytes())
+}
+
+func handleServiceError(g *gin.Context, err error) {
+	if errors.Is(err, entity.ErrNotFound) {
+		g.JSON(http.StatusNotFound, gin.H{"error": "Not found!"})
+		return
+	}
+
+	g.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error!"})
+}
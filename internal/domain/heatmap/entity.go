@@ -0,0 +1,92 @@
+package heatmap
+
+import (
+	"math"
+	"time"
+
+	"github.com/Cludch/csgo-tools/internal/demoparser"
+	"github.com/Cludch/csgo-tools/internal/domain/entity"
+)
+
+// Type describes which positions should be drawn on a heatmap.
+type Type string
+
+const (
+	Kills     Type = "kills"
+	Deaths    Type = "deaths"
+	Positions Type = "positions"
+)
+
+// Heatmap holds the kill, death and sampled positions of all players of one match.
+type Heatmap struct {
+	MatchID   entity.ID          `json:"matchId" bson:"_id"`
+	CreatedAt time.Time          `json:"-" bson:"createdAt"`
+	Map       string             `json:"map" bson:"map"`
+	Players   []*PlayerPositions `json:"players" bson:"players"`
+}
+
+// PlayerPositions holds the positions of one player.
+// Positions are stored as flattened pairs of x and y world coordinates to keep the documents small.
+type PlayerPositions struct {
+	SteamID   uint64  `json:"id" bson:"steamId"`
+	Kills     []int16 `json:"kills" bson:"kills"`
+	Deaths    []int16 `json:"deaths" bson:"deaths"`
+	Positions []int16 `json:"positions" bson:"positions"`
+}
+
+// CreateHeatmap collects the kill, death and sampled positions of each player from the parsed match.
+func CreateHeatmap(m *demoparser.MatchData) *Heatmap {
+	h := &Heatmap{MatchID: m.ID, CreatedAt: time.Now(), Map: m.Map}
+	players := make(map[*demoparser.Player]*PlayerPositions)
+
+	getPlayer := func(p *demoparser.Player) *PlayerPositions {
+		if _, found := players[p]; !found {
+			players[p] = &PlayerPositions{SteamID: p.SteamID}
+			h.Players = append(h.Players, players[p])
+		}
+		return players[p]
+	}
+
+	for _, round := range m.Rounds {
+		for _, kill := range round.Kills {
+			if kill.Killer != nil && kill.Killer != kill.Victim {
+				p := getPlayer(kill.Killer)
+				p.Kills = appendPosition(p.Kills, kill.KillerPosition)
+			}
+
+			if kill.Victim != nil {
+				p := getPlayer(kill.Victim)
+				p.Deaths = appendPosition(p.Deaths, kill.VictimPosition)
+			}
+		}
+
+		for _, sample := range round.Positions {
+			p := getPlayer(sample.Player)
+			p.Positions = appendPosition(p.Positions, sample.Position)
+		}
+	}
+
+	return h
+}
+
+// GetPositions returns the flattened positions of the requested type.
+func (p *PlayerPositions) GetPositions(t Type) []int16 {
+	switch t {
+	case Kills:
+		return p.Kills
+	case Deaths:
+		return p.Deaths
+	case Positions:
+		return p.Positions
+	}
+
+	return nil
+}
+
+func appendPosition(positions []int16, position demoparser.Position) []int16 {
+	return append(positions, toInt16(position.X), toInt16(position.Y))
+}
+
+func toInt16(value float64) int16 {
+	return int16(math.Max(math.MinInt16, math.Min(math.MaxInt16, math.Round(value))))
+}
@@ -0,0 +1,125 @@
+package heatmap
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/Cludch/csgo-tools/internal/domain/entity"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type RepositoryMongo struct {
+	db *entity.Service
+}
+
+func NewRepositoryMongo(db *entity.Service) *RepositoryMongo {
+	r := &RepositoryMongo{
+		db: db,
+	}
+
+	r.createIndex()
+
+	return r
+}
+
+func (r *RepositoryMongo) createIndex() {
+	collection := r.getCollection()
+	opts := options.CreateIndexes().SetMaxTime(10 * time.Second)
+
+	models := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "map", Value: 1}, {Key: "players.steamId", Value: 1}},
+			Options: options.Index().SetName("map_players_steamId"),
+		},
+	}
+
+	if _, err := collection.Indexes().CreateMany(context.Background(), models, opts); err != nil {
+		log.Error(err)
+	}
+}
+
+func (r *RepositoryMongo) Save(ctx context.Context, h *Heatmap) error {
+	filter := bson.M{"_id": h.MatchID}
+	opts := options.Replace().SetUpsert(true)
+
+	_, err := r.getCollection().ReplaceOne(ctx, filter, h, opts)
+	return handleError(err)
+}
+
+func (r *RepositoryMongo) Find(ctx context.Context, id entity.ID) (*Heatmap, error) {
+	filterConfig := bson.M{"_id": id}
+	h, err := r.filterOne(ctx, filterConfig)
+	return h, handleError(err)
+}
+
+func (r *RepositoryMongo) ListByMapAndPlayer(ctx context.Context, mapName string, steamID uint64) ([]*Heatmap, error) {
+	filterConfig := bson.M{"map": mapName, "players.steamId": steamID}
+	h, err := r.filter(ctx, filterConfig)
+	return h, handleError(err)
+}
+
+func (r *RepositoryMongo) Delete(ctx context.Context, id entity.ID) error {
+	filter := bson.M{"_id": id}
+
+	_, err := r.getCollection().DeleteOne(ctx, filter)
+	return handleError(err)
+}
+
+func (r *RepositoryMongo) getCollection() *mongo.Collection {
+	return r.db.GetCollection("heatmaps")
+}
+
+func (r *RepositoryMongo) filterOne(ctx context.Context, filter interface{}) (*Heatmap, error) {
+	var h *Heatmap
+	res := r.getCollection().FindOne(ctx, filter)
+	if err := res.Decode(&h); err != nil {
+		return nil, handleError(err)
+	}
+
+	return h, nil
+}
+
+func (r *RepositoryMongo) filter(ctx context.Context, filter interface{}) ([]*Heatmap, error) {
+	var heatmaps []*Heatmap
+
+	cur, err := r.getCollection().Find(ctx, filter)
+	if err != nil {
+		return heatmaps, err
+	}
+
+	for cur.Next(ctx) {
+		var h Heatmap
+		if err := handleError(cur.Decode(&h)); err != nil {
+			return heatmaps, err
+		}
+
+		heatmaps = append(heatmaps, &h)
+	}
+
+	if err := handleError(cur.Err()); err != nil {
+		return heatmaps, err
+	}
+
+	cur.Close(ctx)
+
+	return heatmaps, nil
+}
+
+func handleError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, mongo.ErrNoDocuments) || errors.Is(err, entity.ErrNotFound) {
+		return entity.ErrNotFound
+	} else {
+		const msg = "heatmap.infrastructure: %s"
+		log.Debugf(msg, err)
+		return entity.ErrUnknownInfrastructureError
+	}
+}
@@ -0,0 +1,26 @@
+package heatmap
+
+import (
+	"context"
+
+	"github.com/Cludch/csgo-tools/internal/domain/entity"
+)
+
+type Repository interface {
+	Save(context.Context, *Heatmap) error
+
+	Find(context.Context, entity.ID) (*Heatmap, error)
+
+	ListByMapAndPlayer(ctx context.Context, mapName string, steamID uint64) ([]*Heatmap, error)
+
+	Delete(context.Context, entity.ID) error
+}
+
+type UseCase interface {
+	SaveHeatmap(context.Context, *Heatmap) error
+
+	GetHeatmap(ctx context.Context, matchID entity.ID) (*Heatmap, error)
+	GetPlayerHeatmaps(ctx context.Context, steamID uint64, mapName string) ([]*Heatmap, error)
+
+	DeleteHeatmap(ctx context.Context, matchID entity.ID) error
+}
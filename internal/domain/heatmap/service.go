@@ -0,0 +1,35 @@
+package heatmap
+
+import (
+	"context"
+
+	"github.com/Cludch/csgo-tools/internal/domain/entity"
+)
+
+type Service struct {
+	repo Repository
+}
+
+func NewService(r Repository) *Service {
+	return &Service{
+		repo: r,
+	}
+}
+
+// SaveHeatmap creates or replaces the heatmap of a match.
+func (s *Service) SaveHeatmap(ctx context.Context, h *Heatmap) error {
+	return s.repo.Save(ctx, h)
+}
+
+func (s *Service) GetHeatmap(ctx context.Context, matchID entity.ID) (*Heatmap, error) {
+	return s.repo.Find(ctx, matchID)
+}
+
+// DeleteHeatmap deletes the heatmap of a match if it exists.
+func (s *Service) DeleteHeatmap(ctx context.Context, matchID entity.ID) error {
+	return s.repo.Delete(ctx, matchID)
+}
+
+func (s *Service) GetPlayerHeatmaps(ctx context.Context, steamID uint64, mapName string) ([]*Heatmap, error) {
+	return s.repo.ListByMapAndPlayer(ctx, mapName, steamID)
+}
@@ -0,0 +1,107 @@
+package job
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Cludch/csgo-tools/internal/domain/entity"
+)
+
+// Type describes the processing step a job performs for a match.
+type Type string
+
+const (
+	// Download downloads the demo of a match.
+	Download Type = "Download"
+	// GCLookup requests the download url of a matchmaking match from the GameCoordinator.
+	GCLookup Type = "GCLookup"
+	// Parse parses the demo of a match.
+	Parse Type = "Parse"
+)
+
+// Status describes the state of a job in the queue.
+type Status string
+
+const (
+	Pending Status = "Pending"
+	Running Status = "Running"
+	Done    Status = "Done"
+	// Failed describes a job that failed and has no attempts left.
+	Failed Status = "Failed"
+)
+
+// Lease is the time a claimed job belongs to a worker. A worker has to send heartbeats in order to keep the job,
+// otherwise it is claimed again by another worker after the lease expired.
+const Lease = 2 * time.Minute
+
+// DefaultMaxAttempts is the amount of attempts of a job unless specified otherwise when enqueuing it.
+const DefaultMaxAttempts = 3
+
+// PollInterval is the time a worker waits before claiming again if there was no job to run.
+const PollInterval = 10 * time.Second
+
+// ReleaseTimeout is the time a worker has for updating its current job after it has been cancelled.
+const ReleaseTimeout = 10 * time.Second
+
+// ErrLeaseLost is returned if a worker updates a job whose lease expired and which got claimed by another worker.
+var ErrLeaseLost = errors.New("job: lease lost")
+
+// Job is one processing step of a match in the queue. There is at most one job per type and match.
+type Job struct {
+	ID          entity.ID `json:"id" bson:"_id"`
+	Type        Type      `json:"type" bson:"type"`
+	MatchID     entity.ID `json:"matchId" bson:"matchId"`
+	Status      Status    `json:"status" bson:"status"`
+	Attempts    int       `json:"attempts" bson:"attempts"`
+	MaxAttempts int       `json:"maxAttempts" bson:"maxAttempts"`
+	// RunAt is the time from which on a pending job can be claimed.
+	RunAt time.Time `json:"runAt" bson:"runAt"`
+	// Owner identifies the worker holding the lease of a running job.
+	Owner          string    `json:"owner" bson:"owner,omitempty"`
+	LeaseExpiresAt time.Time `json:"leaseExpiresAt" bson:"leaseExpiresAt,omitempty"`
+	LastError      string    `json:"lastError" bson:"lastError,omitempty"`
+	CreatedAt      time.Time `json:"createdAt" bson:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt" bson:"updatedAt"`
+}
+
+// Wraps an error that should not be retried.
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent marks the error of a job as permanent, thus the job is not retried.
+func Permanent(err error) error {
+	return permanentError{err: err}
+}
+
+// IsPermanent returns whether the error should not be retried.
+func IsPermanent(err error) bool {
+	var p permanentError
+	return errors.As(err, &p)
+}
+
+// HasAttemptsLeft returns whether a failed job gets retried.
+func (j *Job) HasAttemptsLeft() bool {
+	return j.Attempts < j.MaxAttempts
+}
+
+// NewOwner returns an identifier of the worker which is unique across hosts and processes.
+func NewOwner(name string) string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+
+	const owner = "%s@%s:%d"
+	return fmt.Sprintf(owner, name, hostname, os.Getpid())
+}
@@ -0,0 +1,193 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/Cludch/csgo-tools/internal/domain/entity"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type RepositoryMongo struct {
+	db *entity.Service
+}
+
+func NewRepositoryMongo(db *entity.Service) *RepositoryMongo {
+	r := &RepositoryMongo{
+		db: db,
+	}
+
+	r.createIndex()
+
+	return r
+}
+
+func (r *RepositoryMongo) createIndex() {
+	collection := r.getCollection()
+	opts := options.CreateIndexes().SetMaxTime(10 * time.Second)
+
+	models := []mongo.IndexModel{
+		{
+			// Guarantees at most one job per type and match.
+			Keys:    bson.D{{Key: "type", Value: 1}, {Key: "matchId", Value: 1}},
+			Options: options.Index().SetUnique(true).SetName("type_matchId"),
+		},
+		{
+			Keys:    bson.D{{Key: "type", Value: 1}, {Key: "status", Value: 1}, {Key: "runAt", Value: 1}},
+			Options: options.Index().SetName("type_status_runAt"),
+		},
+	}
+
+	if _, err := collection.Indexes().CreateMany(context.Background(), models, opts); err != nil {
+		log.Error(err)
+	}
+}
+
+// Enqueue inserts the job or resets the finished or failed job of the same type and match.
+// A pending or running job is left untouched.
+func (r *RepositoryMongo) Enqueue(ctx context.Context, j *Job) error {
+	filter := bson.M{
+		"type":    j.Type,
+		"matchId": j.MatchID,
+		"status":  bson.M{"$in": []Status{Done, Failed}},
+	}
+
+	update := bson.D{
+		primitive.E{Key: "$set", Value: bson.D{
+			primitive.E{Key: "status", Value: j.Status},
+			primitive.E{Key: "attempts", Value: 0},
+			primitive.E{Key: "maxAttempts", Value: j.MaxAttempts},
+			primitive.E{Key: "runAt", Value: j.RunAt},
+			primitive.E{Key: "lastError", Value: ""},
+			primitive.E{Key: "updatedAt", Value: j.UpdatedAt},
+		}},
+		primitive.E{Key: "$setOnInsert", Value: bson.D{
+			primitive.E{Key: "_id", Value: j.ID},
+			primitive.E{Key: "createdAt", Value: j.CreatedAt},
+		}},
+	}
+
+	_, err := r.getCollection().UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		// The job is pending or running already.
+		return nil
+	}
+
+	return handleError(err)
+}
+
+// Claim atomically takes the oldest runnable job of the type, which is either pending or running with an expired
+// lease, and counts the attempt.
+func (r *RepositoryMongo) Claim(ctx context.Context, t Type, owner string, leaseExpiresAt time.Time) (*Job, error) {
+	now := time.Now()
+	filter := bson.M{
+		"type": t,
+		"$or": []bson.M{
+			{"status": Pending, "runAt": bson.M{"$lte": now}},
+			{"status": Running, "leaseExpiresAt": bson.M{"$lte": now}},
+		},
+	}
+
+	update := bson.D{
+		primitive.E{Key: "$set", Value: bson.D{
+			primitive.E{Key: "status", Value: Running},
+			primitive.E{Key: "owner", Value: owner},
+			primitive.E{Key: "leaseExpiresAt", Value: leaseExpiresAt},
+			primitive.E{Key: "updatedAt", Value: now},
+		}},
+		primitive.E{Key: "$inc", Value: bson.D{
+			primitive.E{Key: "attempts", Value: 1},
+		}},
+	}
+
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "runAt", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	j := &Job{}
+	if err := r.getCollection().FindOneAndUpdate(ctx, filter, update, opts).Decode(j); err != nil {
+		return nil, handleError(err)
+	}
+
+	return j, nil
+}
+
+func (r *RepositoryMongo) Heartbeat(ctx context.Context, j *Job, leaseExpiresAt time.Time) error {
+	filter := bson.M{"_id": j.ID, "owner": j.Owner, "status": Running}
+
+	update := bson.D{primitive.E{Key: "$set", Value: bson.D{
+		primitive.E{Key: "leaseExpiresAt", Value: leaseExpiresAt},
+	}}}
+
+	return r.updateLeased(ctx, filter, update)
+}
+
+// Update updates the state of a job, which has to be leased by the owner of the job, and releases the lease.
+func (r *RepositoryMongo) Update(ctx context.Context, j *Job) error {
+	filter := bson.M{"_id": j.ID, "owner": j.Owner, "status": Running}
+
+	update := bson.D{
+		primitive.E{Key: "$set", Value: bson.D{
+			primitive.E{Key: "status", Value: j.Status},
+			primitive.E{Key: "attempts", Value: j.Attempts},
+			primitive.E{Key: "runAt", Value: j.RunAt},
+			primitive.E{Key: "lastError", Value: j.LastError},
+			primitive.E{Key: "updatedAt", Value: j.UpdatedAt},
+		}},
+		primitive.E{Key: "$unset", Value: bson.D{
+			primitive.E{Key: "owner", Value: ""},
+			primitive.E{Key: "leaseExpiresAt", Value: ""},
+		}},
+	}
+
+	return r.updateLeased(ctx, filter, update)
+}
+
+func (r *RepositoryMongo) FindByMatch(ctx context.Context, t Type, matchID entity.ID) (*Job, error) {
+	filterConfig := bson.M{"type": t, "matchId": matchID}
+
+	j := &Job{}
+	if err := r.getCollection().FindOne(ctx, filterConfig).Decode(j); err != nil {
+		return nil, handleError(err)
+	}
+
+	return j, nil
+}
+
+// Updates a job only if it is still leased by the worker.
+func (r *RepositoryMongo) updateLeased(ctx context.Context, filter interface{}, update interface{}) error {
+	res, err := r.getCollection().UpdateOne(ctx, filter, update)
+	if err != nil {
+		return handleError(err)
+	}
+
+	if res.MatchedCount == 0 {
+		return ErrLeaseLost
+	}
+
+	return nil
+}
+
+func (r *RepositoryMongo) getCollection() *mongo.Collection {
+	return r.db.GetCollection("jobs")
+}
+
+func handleError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, mongo.ErrNoDocuments) || errors.Is(err, entity.ErrNotFound) {
+		return entity.ErrNotFound
+	} else {
+		const msg = "job.infrastructure: %s"
+		log.Debugf(msg, err)
+		return entity.ErrUnknownInfrastructureError
+	}
+}
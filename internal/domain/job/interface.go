@@ -0,0 +1,32 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/Cludch/csgo-tools/internal/domain/entity"
+)
+
+type Repository interface {
+	Enqueue(context.Context, *Job) error
+
+	Claim(ctx context.Context, t Type, owner string, leaseExpiresAt time.Time) (*Job, error)
+	Heartbeat(ctx context.Context, j *Job, leaseExpiresAt time.Time) error
+	Update(context.Context, *Job) error
+
+	FindByMatch(ctx context.Context, t Type, matchID entity.ID) (*Job, error)
+}
+
+type UseCase interface {
+	Enqueue(ctx context.Context, t Type, matchID entity.ID, maxAttempts int) error
+
+	Claim(ctx context.Context, t Type, owner string) (*Job, error)
+	Heartbeat(context.Context, *Job) error
+	KeepAlive(ctx context.Context, j *Job, lost context.CancelFunc) (stop func())
+	Complete(context.Context, *Job) error
+	Fail(ctx context.Context, j *Job, err error, backoff time.Duration) error
+	Release(context.Context, *Job) error
+	Run(ctx context.Context, t Type, owner string, handler func(context.Context, *Job) error, backoff func(attempts int) time.Duration)
+
+	GetJob(ctx context.Context, t Type, matchID entity.ID) (*Job, error)
+}
@@ -0,0 +1,203 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Cludch/csgo-tools/internal/domain/entity"
+	log "github.com/sirupsen/logrus"
+)
+
+type Service struct {
+	// HeartbeatInterval is the time between two heartbeats of a running job.
+	HeartbeatInterval time.Duration
+	repo              Repository
+}
+
+func NewService(r Repository) *Service {
+	return &Service{
+		HeartbeatInterval: Lease / 4,
+		repo:              r,
+	}
+}
+
+// Enqueue adds a pending job for the match. A job that is pending or running already is kept as it is, while a
+// finished or failed job is reset, thus it runs again.
+func (s *Service) Enqueue(ctx context.Context, t Type, matchID entity.ID, maxAttempts int) error {
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+
+	now := time.Now()
+	return s.repo.Enqueue(ctx, &Job{
+		ID:          entity.NewID(),
+		Type:        t,
+		MatchID:     matchID,
+		Status:      Pending,
+		MaxAttempts: maxAttempts,
+		RunAt:       now,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	})
+}
+
+// Claim takes the next pending job of the type or a running job whose lease expired.
+// Returns entity.ErrNotFound if there is no job to run.
+func (s *Service) Claim(ctx context.Context, t Type, owner string) (*Job, error) {
+	return s.repo.Claim(ctx, t, owner, time.Now().Add(Lease))
+}
+
+// Heartbeat extends the lease of a running job. Returns ErrLeaseLost if the job belongs to another worker.
+func (s *Service) Heartbeat(ctx context.Context, j *Job) error {
+	leaseExpiresAt := time.Now().Add(Lease)
+	if err := s.repo.Heartbeat(ctx, j, leaseExpiresAt); err != nil {
+		return err
+	}
+
+	j.LeaseExpiresAt = leaseExpiresAt
+	return nil
+}
+
+// KeepAlive sends heartbeats for the job until stop is called or the context is cancelled. Once the lease got lost to
+// another worker, lost is called and no further heartbeats are sent. Stop returns after the last heartbeat finished,
+// thus the job can be updated safely afterwards.
+func (s *Service) KeepAlive(ctx context.Context, j *Job, lost context.CancelFunc) (stop func()) {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+
+		t := time.NewTicker(s.HeartbeatInterval)
+		defer t.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-t.C:
+				err := s.Heartbeat(ctx, j)
+				if errors.Is(err, ErrLeaseLost) {
+					const msg = "job: lost lease of %s job %s of match %s"
+					log.Warnf(msg, j.Type, j.ID, j.MatchID)
+					lost()
+					return
+				}
+
+				if err != nil {
+					const msg = "job: unable to extend lease of %s job %s: %s"
+					log.Errorf(msg, j.Type, j.ID, err)
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// Complete marks the job as done.
+func (s *Service) Complete(ctx context.Context, j *Job) error {
+	j.Status = Done
+	j.LastError = ""
+
+	return s.update(ctx, j)
+}
+
+// Fail records the error of the job. The job is retried after the backoff as long as it has attempts left and the
+// error is not permanent.
+func (s *Service) Fail(ctx context.Context, j *Job, jobErr error, backoff time.Duration) error {
+	j.LastError = jobErr.Error()
+
+	if j.HasAttemptsLeft() && !IsPermanent(jobErr) {
+		j.Status = Pending
+		j.RunAt = time.Now().Add(backoff)
+	} else {
+		j.Status = Failed
+
+		const msg = "job: %s job %s of match %s failed after %d attempts: %s"
+		log.Warnf(msg, j.Type, j.ID, j.MatchID, j.Attempts, jobErr)
+	}
+
+	return s.update(ctx, j)
+}
+
+// Release returns the job to the queue without counting the attempt, e.g. because the worker is shutting down.
+func (s *Service) Release(ctx context.Context, j *Job) error {
+	j.Status = Pending
+	j.RunAt = time.Now()
+	if j.Attempts > 0 {
+		j.Attempts--
+	}
+
+	return s.update(ctx, j)
+}
+
+// Run claims jobs of the type and runs them using the handler until the context is cancelled. Jobs are completed if
+// the handler succeeds and failed otherwise, in which case they are retried after the backoff for the amount of
+// attempts so far. The lease of a job is kept alive while the handler runs.
+// The handler gets a context of the job passed and should return once it is cancelled. A job whose handler failed due
+// to the cancellation is released, thus it is claimed again right away by the next worker. The context of the job is
+// also cancelled if its lease got lost, as the job is run by another worker then and is left as it is.
+func (s *Service) Run(ctx context.Context, t Type, owner string, handler func(context.Context, *Job) error, backoff func(attempts int) time.Duration) {
+	for ctx.Err() == nil {
+		j, err := s.Claim(ctx, t, owner)
+		if err != nil {
+			if !errors.Is(err, entity.ErrNotFound) && ctx.Err() == nil {
+				log.Error(err)
+			}
+
+			select {
+			case <-ctx.Done():
+			case <-time.After(PollInterval):
+			}
+			continue
+		}
+
+		jobCtx, cancelJob := context.WithCancel(ctx)
+		stop := s.KeepAlive(jobCtx, j, cancelJob)
+		handlerErr := handler(jobCtx, j)
+		stop()
+
+		leaseLost := jobCtx.Err() != nil && ctx.Err() == nil
+		cancelJob()
+
+		if leaseLost {
+			continue
+		}
+
+		// The job is updated even if the context has been cancelled in the meantime.
+		updateCtx, cancel := context.WithTimeout(context.Background(), ReleaseTimeout)
+		switch {
+		case handlerErr == nil:
+			err = s.Complete(updateCtx, j)
+		case ctx.Err() != nil:
+			const msg = "job: releasing %s job %s of match %s"
+			log.Infof(msg, j.Type, j.ID, j.MatchID)
+			err = s.Release(updateCtx, j)
+		default:
+			err = s.Fail(updateCtx, j, handlerErr, backoff(j.Attempts))
+		}
+		cancel()
+
+		if err != nil {
+			const msg = "job: unable to update %s job %s: %s"
+			log.Errorf(msg, j.Type, j.ID, err)
+		}
+	}
+}
+
+func (s *Service) GetJob(ctx context.Context, t Type, matchID entity.ID) (*Job, error) {
+	return s.repo.FindByMatch(ctx, t, matchID)
+}
+
+// Updates the job and releases the lease.
+func (s *Service) update(ctx context.Context, j *Job) error {
+	j.UpdatedAt = time.Now()
+
+	return s.repo.Update(ctx, j)
+}
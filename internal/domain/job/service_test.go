@@ -0,0 +1,252 @@
+package job_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Cludch/csgo-tools/internal/domain/entity"
+	"github.com/Cludch/csgo-tools/internal/domain/job"
+	"github.com/stretchr/testify/assert"
+)
+
+// Keeps the last updated job in memory and returns the claimable job once.
+type repositoryMock struct {
+	updated      *job.Job
+	claimable    *job.Job
+	heartbeatErr error
+}
+
+func (r *repositoryMock) Enqueue(context.Context, *job.Job) error { return nil }
+func (r *repositoryMock) Claim(context.Context, job.Type, string, time.Time) (*job.Job, error) {
+	if j := r.claimable; j != nil {
+		r.claimable = nil
+		return j, nil
+	}
+	return nil, entity.ErrNotFound
+}
+func (r *repositoryMock) Heartbeat(context.Context, *job.Job, time.Time) error { return r.heartbeatErr }
+func (r *repositoryMock) Update(_ context.Context, j *job.Job) error {
+	r.updated = j
+	return nil
+}
+func (r *repositoryMock) FindByMatch(context.Context, job.Type, entity.ID) (*job.Job, error) {
+	return nil, entity.ErrNotFound
+}
+
+// Holds a single job and checks its lease by the owner like the database does. The lease expires once expire is
+// called.
+type leaseRepositoryMock struct {
+	mu        sync.Mutex
+	stored    job.Job
+	expired   bool
+	updatedBy []string
+}
+
+func (r *leaseRepositoryMock) Enqueue(context.Context, *job.Job) error { return nil }
+func (r *leaseRepositoryMock) Claim(_ context.Context, _ job.Type, owner string, _ time.Time) (*job.Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.stored.Status != job.Pending && !(r.stored.Status == job.Running && r.expired) {
+		return nil, entity.ErrNotFound
+	}
+
+	r.stored.Status = job.Running
+	r.stored.Owner = owner
+	r.stored.Attempts++
+	r.expired = false
+
+	j := r.stored
+	return &j, nil
+}
+func (r *leaseRepositoryMock) Heartbeat(_ context.Context, j *job.Job, _ time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.stored.Status != job.Running || r.stored.Owner != j.Owner {
+		return job.ErrLeaseLost
+	}
+	return nil
+}
+func (r *leaseRepositoryMock) Update(_ context.Context, j *job.Job) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.stored.Status != job.Running || r.stored.Owner != j.Owner {
+		return job.ErrLeaseLost
+	}
+
+	r.stored = *j
+	r.stored.Owner = ""
+	r.updatedBy = append(r.updatedBy, j.Owner)
+	return nil
+}
+func (r *leaseRepositoryMock) FindByMatch(context.Context, job.Type, entity.ID) (*job.Job, error) {
+	return nil, entity.ErrNotFound
+}
+
+func (r *leaseRepositoryMock) expire() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.expired = true
+}
+
+func newRunningJob(attempts int) *job.Job {
+	return &job.Job{ID: entity.NewID(), Type: job.Download, Status: job.Running, Attempts: attempts, MaxAttempts: 3}
+}
+
+func TestFailRetries(t *testing.T) {
+	repo := &repositoryMock{}
+	service := job.NewService(repo)
+	j := newRunningJob(1)
+
+	assert.Nil(t, service.Fail(context.Background(), j, errors.New("timeout"), time.Hour))
+
+	assert.Equal(t, j, repo.updated)
+	assert.Equal(t, job.Pending, j.Status)
+	assert.Equal(t, "timeout", j.LastError)
+	assert.True(t, j.RunAt.After(time.Now().Add(59*time.Minute)))
+}
+
+func TestFailWithoutAttemptsLeft(t *testing.T) {
+	service := job.NewService(&repositoryMock{})
+	j := newRunningJob(3)
+
+	assert.Nil(t, service.Fail(context.Background(), j, errors.New("timeout"), time.Hour))
+	assert.Equal(t, job.Failed, j.Status)
+}
+
+func TestFailPermanent(t *testing.T) {
+	service := job.NewService(&repositoryMock{})
+	j := newRunningJob(1)
+	err := job.Permanent(entity.ErrNotFound)
+
+	assert.True(t, job.IsPermanent(err))
+	assert.True(t, errors.Is(err, entity.ErrNotFound))
+	assert.False(t, job.IsPermanent(entity.ErrNotFound))
+
+	assert.Nil(t, service.Fail(context.Background(), j, err, time.Hour))
+	assert.Equal(t, job.Failed, j.Status)
+	assert.Equal(t, entity.ErrNotFound.Error(), j.LastError)
+}
+
+func TestComplete(t *testing.T) {
+	service := job.NewService(&repositoryMock{})
+	j := newRunningJob(2)
+	j.LastError = "timeout"
+
+	assert.Nil(t, service.Complete(context.Background(), j))
+	assert.Equal(t, job.Done, j.Status)
+	assert.Empty(t, j.LastError)
+}
+
+func TestRelease(t *testing.T) {
+	repo := &repositoryMock{}
+	service := job.NewService(repo)
+	j := newRunningJob(2)
+
+	assert.Nil(t, service.Release(context.Background(), j))
+
+	assert.Equal(t, j, repo.updated)
+	assert.Equal(t, job.Pending, j.Status)
+	assert.Equal(t, 1, j.Attempts)
+	assert.False(t, j.RunAt.After(time.Now()))
+}
+
+func TestRunReleasesJobOnCancel(t *testing.T) {
+	repo := &repositoryMock{claimable: newRunningJob(1)}
+	service := job.NewService(repo)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		service.Run(ctx, job.Download, "test", func(ctx context.Context, j *job.Job) error {
+			cancel()
+			<-ctx.Done()
+			return ctx.Err()
+		}, func(int) time.Duration { return time.Hour })
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("run did not return after the context has been cancelled")
+	}
+
+	assert.Equal(t, job.Pending, repo.updated.Status)
+	assert.Equal(t, 0, repo.updated.Attempts)
+}
+
+func TestRunStopsJobOnLeaseLost(t *testing.T) {
+	repo := &repositoryMock{claimable: newRunningJob(1), heartbeatErr: job.ErrLeaseLost}
+	service := job.NewService(repo)
+	service.HeartbeatInterval = time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cancelled := make(chan error, 1)
+	go service.Run(ctx, job.Download, "test", func(ctx context.Context, j *job.Job) error {
+		select {
+		case <-ctx.Done():
+			cancelled <- ctx.Err()
+		case <-time.After(time.Second):
+			cancelled <- nil
+		}
+		return ctx.Err()
+	}, func(int) time.Duration { return time.Hour })
+
+	assert.Equal(t, context.Canceled, <-cancelled)
+
+	// The job belongs to the worker that took over the lease.
+	time.Sleep(10 * time.Millisecond)
+	assert.Nil(t, repo.updated)
+}
+
+func TestRunWithTwoWorkersOfOneProcess(t *testing.T) {
+	repo := &leaseRepositoryMock{stored: job.Job{ID: entity.NewID(), Type: job.Download, Status: job.Pending, MaxAttempts: 3}}
+	service := job.NewService(repo)
+	service.HeartbeatInterval = time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	first := job.NewOwner("download-1")
+	second := job.NewOwner("download-2")
+	assert.NotEqual(t, first, second)
+
+	// The first worker stalls until its lease got taken over.
+	started := make(chan struct{})
+	cancelled := make(chan error, 1)
+	go service.Run(ctx, job.Download, first, func(ctx context.Context, j *job.Job) error {
+		close(started)
+		select {
+		case <-ctx.Done():
+			cancelled <- ctx.Err()
+		case <-time.After(time.Second):
+			cancelled <- nil
+		}
+		return ctx.Err()
+	}, func(int) time.Duration { return time.Hour })
+
+	<-started
+	repo.expire()
+
+	// The second worker keeps the job until the first one stopped, thus the first worker has to notice by the owner.
+	completed := make(chan error, 1)
+	go service.Run(ctx, job.Download, second, func(ctx context.Context, j *job.Job) error {
+		completed <- <-cancelled
+		return nil
+	}, func(int) time.Duration { return time.Hour })
+
+	assert.Equal(t, context.Canceled, <-completed)
+
+	time.Sleep(10 * time.Millisecond)
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	assert.Equal(t, []string{second}, repo.updatedBy)
+	assert.Equal(t, job.Done, repo.stored.Status)
+	assert.Equal(t, 2, repo.stored.Attempts)
+}
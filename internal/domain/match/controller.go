@@ -1,25 +1,33 @@
 package match
 
 import (
+	"errors"
 	"net/http"
+	"os"
+	"strconv"
 
 	"github.com/Cludch/csgo-tools/internal/domain/entity"
+	"github.com/Cludch/csgo-tools/pkg/demo"
 	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
 )
 
 type Controller struct {
-	service UseCase
+	service   UseCase
+	demoStore demo.DemoStore
 }
 
-func NewController(s UseCase) *Controller {
+// NewController creates a controller which serves the round replays stored next to the demos in the store.
+func NewController(s UseCase, demoStore demo.DemoStore) *Controller {
 	return &Controller{
-		service: s,
+		service:   s,
+		demoStore: demoStore,
 	}
 }
 
 // GetMatches returns all matches from the database.
 func (c *Controller) GetMatches(g *gin.Context) {
-	matches, _ := c.service.GetAllParsed()
+	matches, _ := c.service.GetAllParsed(g.Request.Context())
 	matchList := &MatchList{Matches: make([]*MatchListEntry, len(matches))}
 
 	clanPlayerIds := getClanPlayersIds()
@@ -59,10 +67,121 @@ func (c *Controller) GetMatchDetails(g *gin.Context) {
 		g.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error!"})
 	}
 
-	match, _ := c.service.GetMatch(id)
+	match, _ := c.service.GetMatch(g.Request.Context(), id)
 	g.JSON(http.StatusOK, match)
 }
 
+// GetMatchDamage returns the damage matrix and the damage breakdown of every player of one match.
+func (c *Controller) GetMatchDamage(g *gin.Context) {
+	id, err := entity.StringToID(g.Param("id"))
+	if err != nil {
+		g.JSON(http.StatusBadRequest, gin.H{"error": "Invalid match id!"})
+		return
+	}
+
+	match, err := c.service.GetMatch(g.Request.Context(), id)
+	if err != nil {
+		g.JSON(http.StatusNotFound, gin.H{"error": "Match not found!"})
+		return
+	}
+
+	g.JSON(http.StatusOK, NewMatchDamage(match))
+}
+
+// GetMatchOpeningDuels returns the opening duels of every player and the head-to-head matrix of one match.
+func (c *Controller) GetMatchOpeningDuels(g *gin.Context) {
+	id, err := entity.StringToID(g.Param("id"))
+	if err != nil {
+		g.JSON(http.StatusBadRequest, gin.H{"error": "Invalid match id!"})
+		return
+	}
+
+	match, err := c.service.GetMatch(g.Request.Context(), id)
+	if err != nil {
+		g.JSON(http.StatusNotFound, gin.H{"error": "Match not found!"})
+		return
+	}
+
+	g.JSON(http.StatusOK, NewMatchOpeningDuels(match))
+}
+
+// GetSiteStats returns the bomb site statistics across all parsed matches on one map.
+func (c *Controller) GetSiteStats(g *gin.Context) {
+	mapName := g.Param("map")
+
+	matches, err := c.service.GetParsedMatchesByMap(g.Request.Context(), mapName)
+	if err != nil {
+		log.Error(err)
+		g.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error!"})
+		return
+	}
+
+	g.JSON(http.StatusOK, NewMapSiteStats(mapName, matches))
+}
+
+// GetRoundTimeline returns the ordered events of one round.
+func (c *Controller) GetRoundTimeline(g *gin.Context) {
+	id, err := entity.StringToID(g.Param("id"))
+	if err != nil {
+		g.JSON(http.StatusBadRequest, gin.H{"error": "Invalid match id!"})
+		return
+	}
+
+	round, err := strconv.Atoi(g.Param("n"))
+	if err != nil {
+		g.JSON(http.StatusBadRequest, gin.H{"error": "Invalid round!"})
+		return
+	}
+
+	match, err := c.service.GetMatch(g.Request.Context(), id)
+	if err != nil {
+		g.JSON(http.StatusNotFound, gin.H{"error": "Match not found!"})
+		return
+	}
+
+	timeline := NewRoundTimeline(match, round)
+	if timeline == nil {
+		g.JSON(http.StatusNotFound, gin.H{"error": "Round not found!"})
+		return
+	}
+
+	g.JSON(http.StatusOK, timeline)
+}
+
+// GetRoundReplay serves the gzipped replay file of one round.
+func (c *Controller) GetRoundReplay(g *gin.Context) {
+	id, err := entity.StringToID(g.Param("id"))
+	if err != nil {
+		g.JSON(http.StatusBadRequest, gin.H{"error": "Invalid match id!"})
+		return
+	}
+
+	round, err := strconv.ParseUint(g.Param("n"), 10, 8)
+	if err != nil || round == 0 {
+		g.JSON(http.StatusBadRequest, gin.H{"error": "Invalid round!"})
+		return
+	}
+
+	match, err := c.service.GetMatch(g.Request.Context(), id)
+	if err != nil || match.Filename == "" {
+		g.JSON(http.StatusNotFound, gin.H{"error": "Match not found!"})
+		return
+	}
+
+	replay, err := c.demoStore.Open(g.Request.Context(), demo.ReplayFilename(match.Filename, byte(round)))
+	if errors.Is(err, os.ErrNotExist) {
+		g.JSON(http.StatusNotFound, gin.H{"error": "Replay not found!"})
+		return
+	} else if err != nil {
+		log.Error(err)
+		g.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error!"})
+		return
+	}
+	defer replay.Close()
+
+	g.DataFromReader(http.StatusOK, -1, "application/json", replay, map[string]string{"Content-Encoding": "gzip"})
+}
+
 // Used to determine whether we (the clan) have won the game.
 // This makes the api highly subjective and is planned to get changed eventually.
 func getClanPlayersIds() map[uint64]bool {
@@ -0,0 +1,38 @@
+package match
+
+import "time"
+
+// maxDownloadBackoff limits the delay between two download attempts.
+const maxDownloadBackoff = 24 * time.Hour
+
+// FailDownload records a failed attempt to download the demo. The attempts are mirrored from the download job. Once no
+// attempts are left, the status changes to Error.
+func (m *Match) FailDownload(attempts int, maxAttempts int, downloadErr error) error {
+	m.DownloadAttempts = attempts
+	m.MaxDownloadAttempts = maxAttempts
+	m.LastError = downloadErr.Error()
+
+	if attempts >= maxAttempts {
+		return m.SetStatus(Error, m.LastError)
+	}
+
+	return nil
+}
+
+// DownloadBackoff returns the delay before the next download attempt after the amount of failed attempts.
+// The delay doubles with every attempt starting at base.
+func DownloadBackoff(attempts int, base time.Duration) time.Duration {
+	if attempts < 1 {
+		return 0
+	}
+
+	backoff := base
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= maxDownloadBackoff {
+			return maxDownloadBackoff
+		}
+	}
+
+	return backoff
+}
@@ -0,0 +1,33 @@
+package match_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Cludch/csgo-tools/internal/domain/match"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDownloadBackoff(t *testing.T) {
+	assert.Equal(t, time.Duration(0), match.DownloadBackoff(0, time.Minute))
+	assert.Equal(t, time.Minute, match.DownloadBackoff(1, time.Minute))
+	assert.Equal(t, 2*time.Minute, match.DownloadBackoff(2, time.Minute))
+	assert.Equal(t, 8*time.Minute, match.DownloadBackoff(4, time.Minute))
+	assert.Equal(t, 24*time.Hour, match.DownloadBackoff(20, time.Minute))
+}
+
+func TestFailDownload(t *testing.T) {
+	m := &match.Match{Status: match.Downloadable}
+
+	assert.Nil(t, m.FailDownload(1, 2, errors.New("timeout")))
+	assert.Equal(t, match.Downloadable, m.Status)
+	assert.Equal(t, 1, m.DownloadAttempts)
+	assert.Equal(t, 2, m.MaxDownloadAttempts)
+	assert.Equal(t, "timeout", m.LastError)
+
+	assert.Nil(t, m.FailDownload(2, 2, errors.New("connection reset")))
+	assert.Equal(t, match.Error, m.Status)
+	assert.Equal(t, "connection reset", m.LastError)
+	assert.Equal(t, "connection reset", m.StatusHistory[len(m.StatusHistory)-1].Reason)
+}
@@ -0,0 +1,90 @@
+package match
+
+import "errors"
+
+// ErrDuplicateDemo is returned if a demo belongs to an existing match.
+var ErrDuplicateDemo = errors.New("match: duplicate demo")
+
+// HasDemo returns whether the demo file of the match is available.
+func (m *Match) HasDemo() bool {
+	return m.Filename != "" && m.FileState != FileDeleted
+}
+
+// Merge merges a duplicate of the same demo into the match. The match keeps the identifiers of all sources and
+// remembers the demo file of the duplicate. If the match has no demo file, it takes over the demo of the duplicate.
+// Returns a *TransitionError if the match can not take over the demo.
+func (m *Match) Merge(duplicate *Match) error {
+	m.addSource(duplicate.Source)
+	for _, source := range duplicate.Sources {
+		m.addSource(source)
+	}
+
+	if m.FaceitMatchId == "" {
+		m.FaceitMatchId = duplicate.FaceitMatchId
+	}
+
+	if m.ShareCode == nil {
+		m.ShareCode = duplicate.ShareCode
+	}
+
+	if m.DownloadURL == "" {
+		m.DownloadURL = duplicate.DownloadURL
+	}
+
+	if m.Time.IsZero() {
+		m.Time = duplicate.Time
+	}
+
+	if m.Fingerprint == nil {
+		m.Fingerprint = duplicate.Fingerprint
+	}
+
+	if !m.HasDemo() && duplicate.HasDemo() {
+		// The demo has to be parsed for this match.
+		if m.Status != Parsed {
+			if err := m.SetStatus(Downloaded, "took over the demo of duplicate match "+duplicate.ID.String()); err != nil {
+				return err
+			}
+		}
+
+		m.Filename = duplicate.Filename
+		m.FileState = duplicate.FileState
+		m.Download = duplicate.Download
+	} else if duplicate.Filename != "" {
+		m.addDuplicateFile(duplicate.Filename)
+	}
+
+	for _, filename := range duplicate.DuplicateFiles {
+		m.addDuplicateFile(filename)
+	}
+
+	return nil
+}
+
+func (m *Match) addSource(source Source) {
+	if source == m.Source {
+		return
+	}
+
+	for _, s := range m.Sources {
+		if s == source {
+			return
+		}
+	}
+
+	m.Sources = append(m.Sources, source)
+}
+
+func (m *Match) addDuplicateFile(filename string) {
+	if filename == m.Filename {
+		return
+	}
+
+	for _, f := range m.DuplicateFiles {
+		if f == filename {
+			return
+		}
+	}
+
+	m.DuplicateFiles = append(m.DuplicateFiles, filename)
+}
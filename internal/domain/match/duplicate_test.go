@@ -0,0 +1,61 @@
+package match_test
+
+import (
+	"testing"
+
+	"github.com/Cludch/csgo-tools/internal/domain/match"
+	"github.com/Cludch/csgo-tools/pkg/share_code"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerge(t *testing.T) {
+	m, _ := match.NewMatch(match.MatchMaking)
+	m.ShareCode = &share_code.ShareCodeData{MatchID: 1}
+
+	upload, _ := match.NewMatch(match.Manual)
+	upload.Filename = "upload.dem"
+	upload.FileState = match.FilePresent
+	upload.Status = match.Downloaded
+
+	assert.Nil(t, m.Merge(upload))
+
+	// The match takes over the demo of the upload as it has not been downloaded yet.
+	assert.Equal(t, "upload.dem", m.Filename)
+	assert.Equal(t, match.Downloaded, m.Status)
+	assert.Equal(t, []match.Source{match.Manual}, m.Sources)
+	assert.Equal(t, uint64(1), m.ShareCode.MatchID)
+	assert.Empty(t, m.DuplicateFiles)
+
+	faceit, _ := match.NewMatch(match.Faceit)
+	faceit.FaceitMatchId = "1-abc"
+	faceit.Filename = "faceit.dem"
+	faceit.FileState = match.FilePresent
+	faceit.DuplicateFiles = []string{"copy.dem", "upload.dem"}
+
+	assert.Nil(t, m.Merge(faceit))
+	assert.Nil(t, m.Merge(faceit))
+
+	assert.Equal(t, "upload.dem", m.Filename)
+	assert.Equal(t, "1-abc", m.FaceitMatchId)
+	assert.Equal(t, []match.Source{match.Manual, match.Faceit}, m.Sources)
+	assert.Equal(t, []string{"faceit.dem", "copy.dem"}, m.DuplicateFiles)
+}
+
+func TestMergeDeletedDemo(t *testing.T) {
+	m, _ := match.NewMatch(match.Manual)
+	m.Filename = "deleted.dem"
+	m.FileState = match.FileDeleted
+	m.Status = match.Parsed
+
+	duplicate, _ := match.NewMatch(match.Manual)
+	duplicate.Filename = "copy.dem.zst"
+	duplicate.FileState = match.FileArchived
+
+	assert.Nil(t, m.Merge(duplicate))
+
+	assert.True(t, m.HasDemo())
+	assert.Equal(t, "copy.dem.zst", m.Filename)
+	assert.Equal(t, match.Parsed, m.Status)
+	assert.Empty(t, m.DuplicateFiles)
+	assert.Empty(t, m.Sources)
+}
@@ -1,14 +1,17 @@
 package match
 
 import (
+	"strings"
 	"time"
 
 	"github.com/Cludch/csgo-tools/internal/demoparser"
 	"github.com/Cludch/csgo-tools/internal/domain/entity"
 	"github.com/Cludch/csgo-tools/internal/domain/player"
+	"github.com/Cludch/csgo-tools/pkg/demo"
 	"github.com/Cludch/csgo-tools/pkg/share_code"
 	"github.com/go-playground/validator"
 	"github.com/markus-wa/demoinfocs-golang/v2/pkg/demoinfocs/common"
+	"github.com/markus-wa/demoinfocs-golang/v2/pkg/demoinfocs/events"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -36,29 +39,92 @@ const (
 	Parsed       Status = "Parsed"
 )
 
+// FileState describes whether the demo file of a match is stored on disk.
+type FileState string
+
+const (
+	// FilePresent describes an uncompressed demo file.
+	FilePresent FileState = "Present"
+	// FileArchived describes a demo file that has been compressed in order to save space.
+	FileArchived FileState = "Archived"
+	// FileDeleted describes a demo file that has been deleted by the retention rules.
+	FileDeleted FileState = "Deleted"
+)
+
+// FileStateOf returns whether the demo file is uncompressed or archived.
+func FileStateOf(filename string) FileState {
+	if strings.HasSuffix(filename, demo.Extension) {
+		return FilePresent
+	}
+
+	return FileArchived
+}
+
 // Match holds the central information about a csgo match from different data sources.
 type Match struct {
-	ID            entity.ID                 `json:"id" bson:"_id,omitempty"`
-	CreatedAt     time.Time                 `json:"-" bson:"createdAt"`
-	Source        Source                    `json:"source" bson:"source" validate:"required"`
-	Status        Status                    `json:"status" bson:"status" validate:"required"`
-	Time          time.Time                 `json:"time" bson:"time,omitempty"`
-	Filename      string                    `json:"filename" bson:"filename,omitempty"`
+	ID        entity.ID `json:"id" bson:"_id,omitempty"`
+	CreatedAt time.Time `json:"-" bson:"createdAt"`
+	Source    Source    `json:"source" bson:"source" validate:"required"`
+	Status    Status    `json:"status" bson:"status" validate:"required"`
+	// StatusHistory records every change of the status.
+	StatusHistory []*StatusChange `json:"statusHistory" bson:"statusHistory,omitempty"`
+	// statusChanges holds the changes of the status history that have not been saved yet.
+	statusChanges []*StatusChange
+	Time          time.Time     `json:"time" bson:"time,omitempty"`
+	Filename      string        `json:"filename" bson:"filename,omitempty"`
+	FileState     FileState     `json:"fileState" bson:"fileState,omitempty"`
+	Download      *DownloadInfo `json:"download" bson:"download,omitempty"`
+	// Fingerprint identifies the demo in order to detect duplicates.
+	Fingerprint *demo.Fingerprint `json:"fingerprint" bson:"fingerprint,omitempty"`
+	// Sources holds the sources of merged duplicates besides Source.
+	Sources []Source `json:"sources" bson:"sources,omitempty"`
+	// DuplicateFiles holds the filenames of further copies of the demo.
+	DuplicateFiles []string `json:"duplicateFiles" bson:"duplicateFiles,omitempty"`
+	// DownloadAttempts and MaxDownloadAttempts mirror the attempts of the download job, which decides about retries.
+	DownloadAttempts    int `json:"downloadAttempts" bson:"downloadAttempts,omitempty"`
+	MaxDownloadAttempts int `json:"maxDownloadAttempts" bson:"maxDownloadAttempts,omitempty"`
+	// LastError holds the error message of the last failed processing step.
+	LastError     string                    `json:"lastError" bson:"lastError,omitempty"`
 	DownloadURL   string                    `json:"url" bson:"url,omitempty"`
 	ShareCode     *share_code.ShareCodeData `json:"shareCode" bson:"shareCode,omitempty"`
 	FaceitMatchId string                    `json:"faceitMatchId" bson:"faceitMatchId,omitempty"`
 	Result        *MatchResult              `json:"result" bson:"result,omitempty" validation:"dive"`
 }
 
+// DownloadInfo describes the download of the demo.
+type DownloadInfo struct {
+	// Size is the size of the downloaded, compressed file in bytes.
+	Size     int64         `json:"size" bson:"size"`
+	Duration time.Duration `json:"duration" bson:"duration"`
+	// Checksum is the hex encoded SHA-256 hash of the uncompressed demo.
+	Checksum string    `json:"checksum" bson:"checksum"`
+	Time     time.Time `json:"time" bson:"time"`
+}
+
 // MatchResult holds meta data and the teams of one match.
 type MatchResult struct {
-	ParserVersion byte          `json:"parserVersion" bson:"parserVersion" validate:"required,gt=0"`
-	Map           string        `json:"map" bson:"map" validate:"required"`
-	Time          time.Time     `json:"time" bson:"time" validate:"required"`
-	Duration      time.Duration `json:"duration" bson:"duration" validate:"required"`
+	ParserVersion     byte              `json:"parserVersion" bson:"parserVersion" validate:"required,gt=0"`
+	Map               string            `json:"map" bson:"map" validate:"required"`
+	Time              time.Time         `json:"time" bson:"time" validate:"required"`
+	Duration          time.Duration     `json:"duration" bson:"duration" validate:"required"`
+	Format            demoparser.Format `json:"format" bson:"format"`
+	MaxRounds         int               `json:"maxRounds" bson:"maxRounds"`
+	OvertimeMaxRounds int               `json:"overtimeMaxRounds" bson:"overtimeMaxRounds"`
+	// Overtimes contains the amount of played overtimes.
+	Overtimes byte `json:"overtimes" bson:"overtimes"`
 	// 0 = T / 1 = CT
 	Teams  []*TeamResult  `json:"teams" validate:"required,dive"`
 	Rounds []*RoundResult `json:"rounds" validate:"required,dive"`
+	Halves []*HalfResult  `json:"halves" bson:"halves" validate:"dive"`
+}
+
+// HalfResult describes the score of one half in regulation or overtime.
+type HalfResult struct {
+	Half byte `json:"half" bson:"half" validate:"required"`
+	// Overtime is the number of the overtime or zero for halves in regulation.
+	Overtime byte `json:"overtime" bson:"overtime"`
+	// 0 = team started as T / 1 = team started as CT
+	Wins []byte `json:"wins" bson:"wins"`
 }
 
 // TeamResult describes the players and wins for one team.
@@ -67,16 +133,113 @@ type TeamResult struct {
 	TeamID          common.Team            `json:"id" bson:"id" validate:"required,gte=2,lte=3"`
 	Players         []*player.PlayerResult `json:"players" bson:"players" validate:"required,dive"`
 	Wins            byte                   `json:"wins" bson:"wins"`
+	TWins           byte                   `json:"tWins" bson:"tWins"`
+	CTWins          byte                   `json:"ctWins" bson:"ctWins"`
 	PistolRoundWins byte                   `json:"pistolRoundWins" bson:"pistolRoundWins"`
 }
 
 // RoundResult contains information about a single round.
 type RoundResult struct {
-	RoundNumber  byte          `json:"roundNumber" bson:"roundNumber" validate:"required"`
-	Duration     time.Duration `json:"duration" bson:"duration" validate:"required"`
-	Kills        []*KillResult `json:"kills" bson:"kills"  validate:"required,dive"`
-	MVPPlayerID  uint64        `json:"mvp" bson:"mvpPlayerId"`
-	WinnerTeamID common.Team   `json:"winnerTeamId" bson:"winnerTeamId" validate:"required,gte=2,lte=3"`
+	RoundNumber       byte                 `json:"roundNumber" bson:"roundNumber" validate:"required"`
+	StartTick         time.Duration        `json:"startTick" bson:"startTick"`
+	FreezetimeEndTick time.Duration        `json:"freezetimeEndTick" bson:"freezetimeEndTick"`
+	Half              byte                 `json:"half" bson:"half"`
+	Overtime          byte                 `json:"overtime" bson:"overtime"`
+	IsPistolRound     bool                 `json:"isPistolRound" bson:"isPistolRound"`
+	Duration          time.Duration        `json:"duration" bson:"duration" validate:"required"`
+	Kills             []*KillResult        `json:"kills" bson:"kills"  validate:"required,dive"`
+	Damage            []*DamageResult      `json:"damage" bson:"damage" validate:"dive"`
+	OpeningDuel       *OpeningDuelResult   `json:"openingDuel" bson:"openingDuel,omitempty"`
+	Clutches          []*ClutchResult      `json:"clutches" bson:"clutches" validate:"dive"`
+	Economy           []*EconomyResult     `json:"economy" bson:"economy" validate:"dive"`
+	TeamEconomy       []*TeamEconomyResult `json:"teamEconomy" bson:"teamEconomy" validate:"dive"`
+	Utility           []*UtilityResult     `json:"utility" bson:"utility" validate:"dive"`
+	Flashes           []*FlashResult       `json:"flashes" bson:"flashes" validate:"dive"`
+	// KASTPlayerIDs contains all players that had a kill, assist, survived or were traded in the round.
+	KASTPlayerIDs []uint64    `json:"kastPlayerIds" bson:"kastPlayerIds"`
+	Bomb          *BombResult `json:"bomb" bson:"bomb,omitempty"`
+	MVPPlayerID   uint64      `json:"mvp" bson:"mvpPlayerId"`
+	WinnerTeamID  common.Team `json:"winnerTeamId" bson:"winnerTeamId" validate:"required,gte=2,lte=3"`
+	// WinnerSide describes the side the winning team played on in this round.
+	WinnerSide common.Team           `json:"winnerSide" bson:"winnerSide"`
+	WinReason  events.RoundEndReason `json:"winReason" bson:"winReason"`
+}
+
+// BombResult contains information about the bomb plant and defuse of a round.
+type BombResult struct {
+	Site            string        `json:"site" bson:"site"`
+	PlanterID       uint64        `json:"planterId" bson:"planterId"`
+	PlantStartTick  time.Duration `json:"plantStartTick" bson:"plantStartTick"`
+	PlantTick       time.Duration `json:"plantTick" bson:"plantTick"`
+	DefuserID       uint64        `json:"defuserId" bson:"defuserId"`
+	DefuseStartTick time.Duration `json:"defuseStartTick" bson:"defuseStartTick"`
+	DefuseTick      time.Duration `json:"defuseTick" bson:"defuseTick"`
+	HasKit          bool          `json:"hasKit" bson:"hasKit"`
+	IsPlanted       bool          `json:"isPlanted" bson:"isPlanted"`
+	IsDefused       bool          `json:"isDefused" bson:"isDefused"`
+	IsExploded      bool          `json:"isExploded" bson:"isExploded"`
+}
+
+// ClutchResult contains information about a player being the last one alive on his team.
+type ClutchResult struct {
+	PlayerID  uint64 `json:"playerId" bson:"playerId"`
+	Opponents byte   `json:"opponents" bson:"opponents" validate:"gte=1,lte=5"`
+	Won       bool   `json:"won" bson:"won"`
+}
+
+// EconomyResult contains the equipment value and money of a player at the end of the freeze time.
+type EconomyResult struct {
+	PlayerID       uint64 `json:"playerId" bson:"playerId"`
+	EquipmentValue int    `json:"equipmentValue" bson:"equipmentValue"`
+	Money          int    `json:"money" bson:"money"`
+	MoneySpent     int    `json:"moneySpent" bson:"moneySpent"`
+}
+
+// TeamEconomyResult contains the equipment value, money and the resulting buy type of a team.
+type TeamEconomyResult struct {
+	// TeamID describes the side the team started as.
+	TeamID         common.Team        `json:"teamId" bson:"teamId" validate:"required,gte=2,lte=3"`
+	EquipmentValue int                `json:"equipmentValue" bson:"equipmentValue"`
+	Money          int                `json:"money" bson:"money"`
+	MoneySpent     int                `json:"moneySpent" bson:"moneySpent"`
+	BuyType        demoparser.BuyType `json:"buyType" bson:"buyType" validate:"required"`
+}
+
+// UtilityResult contains information about a thrown grenade.
+type UtilityResult struct {
+	Tick     time.Duration        `json:"tick" bson:"tick"`
+	PlayerID uint64               `json:"playerId" bson:"playerId"`
+	Type     common.EquipmentType `json:"type" bson:"type" validate:"required"`
+}
+
+// FlashResult contains information about a player being blinded by a flashbang.
+type FlashResult struct {
+	Tick        time.Duration `json:"tick" bson:"tick"`
+	AttackerID  uint64        `json:"attackerId" bson:"attackerId"`
+	VictimID    uint64        `json:"victimId" bson:"victimId"`
+	Duration    time.Duration `json:"duration" bson:"duration"`
+	IsTeamFlash bool          `json:"isTeamFlash" bson:"isTeamFlash"`
+}
+
+// OpeningDuelResult contains information about the first kill of a round.
+type OpeningDuelResult struct {
+	Tick       time.Duration        `json:"tick" bson:"tick"`
+	KillerID   uint64               `json:"killerId" bson:"killerId"`
+	VictimID   uint64               `json:"victimId" bson:"victimId"`
+	Weapon     common.EquipmentType `json:"weapon" bson:"weapon"`
+	KillerSide common.Team          `json:"killerSide" bson:"killerSide"`
+	VictimSide common.Team          `json:"victimSide" bson:"victimSide"`
+}
+
+// DamageResult contains information about a player getting hurt by another player or the world.
+type DamageResult struct {
+	Tick         time.Duration        `json:"tick" bson:"tick"`
+	AttackerID   uint64               `json:"attackerId" bson:"attackerId"`
+	VictimID     uint64               `json:"victimId" bson:"victimId"`
+	Weapon       common.EquipmentType `json:"weapon" bson:"weapon"`
+	HitGroup     events.HitGroup      `json:"hitGroup" bson:"hitGroup"`
+	HealthDamage int                  `json:"healthDamage" bson:"healthDamage"`
+	ArmorDamage  int                  `json:"armorDamage" bson:"armorDamage"`
 }
 
 // KillResult contains information about a kill.
@@ -85,7 +248,7 @@ type KillResult struct {
 	VictimID        uint64               `json:"victimId" bson:"victimId"`
 	KillerID        uint64               `json:"killerId" bson:"killerId"`
 	AssisterID      uint64               `json:"assisterId" bson:"assisterId"`
-	Weapon          common.EquipmentType `json:"weapon" bson:"" validate:"required"`
+	Weapon          common.EquipmentType `json:"weapon" bson:"weapon" validate:"required"`
 	IsDuringRound   bool                 `json:"isDuringRound" bson:"isDuringRound"`
 	IsHeadshot      bool                 `json:"isHeadshot" bson:"isHeadshot"`
 	IsFlashAssist   bool                 `json:"isFlashAssist" bson:"isFlashAssist"`
@@ -93,6 +256,10 @@ type KillResult struct {
 	IsNoScope       bool                 `json:"isNoScope" bson:"isNoScope"`
 	IsThroughSmoke  bool                 `json:"isThroughSmoke" bson:"isThroughSmoke"`
 	IsThroughWall   bool                 `json:"isThroughWall" bson:"isThroughWall"`
+	// IsTrade describes whether the kill avenged a teammate that died shortly before.
+	IsTrade bool `json:"isTrade" bson:"isTrade"`
+	// WasTraded describes whether the killer got killed by a teammate of the victim shortly after.
+	WasTraded bool `json:"wasTraded" bson:"wasTraded"`
 }
 
 func NewMatch(source Source) (*Match, error) {
@@ -100,8 +267,8 @@ func NewMatch(source Source) (*Match, error) {
 		ID:        entity.NewID(),
 		CreatedAt: time.Now(),
 		Source:    source,
-		Status:    Created,
 	}
+	m.SetStatus(Created, "")
 
 	if err := m.Validate(); err != nil {
 		return nil, err
@@ -109,10 +276,15 @@ func NewMatch(source Source) (*Match, error) {
 	return m, nil
 }
 
+// DefaultTradeWindow is the time in which a kill of the killer counts as a trade.
+const DefaultTradeWindow = 5 * time.Second
+
 // Process processes the match data and creates more performance-based results per player in order to persist these in the database.
-func CreateResult(m *demoparser.MatchData) *MatchResult {
+// Kills of a killer within the trade window after he killed someone are considered trades.
+func CreateResult(m *demoparser.MatchData, tradeWindow time.Duration) *MatchResult {
 	// Create result.
-	result := &MatchResult{Map: m.Map, Duration: m.Duration, Time: m.Time, Teams: make([]*TeamResult, 2), Rounds: make([]*RoundResult, len(m.Rounds))}
+	result := &MatchResult{Map: m.Map, Duration: m.Duration, Time: m.Time, Format: m.Format, MaxRounds: m.MaxRounds,
+		OvertimeMaxRounds: m.OvertimeMaxRounds, Teams: make([]*TeamResult, 2), Rounds: make([]*RoundResult, len(m.Rounds))}
 
 	// Create teams.
 	for _, team := range m.Teams {
@@ -133,14 +305,84 @@ func CreateResult(m *demoparser.MatchData) *MatchResult {
 		team.Players = append(team.Players, &player.PlayerResult{SteamID: p.SteamID, MatchID: m.ID, Map: m.Map, Time: m.Time, Name: p.Name, WinCount: p.WinCount, RankOld: p.RankOld, RankNew: p.RankNew})
 	}
 
-	result.processRounds(m.Rounds)
+	result.processRounds(m.Rounds, tradeWindow)
+
+	// Calculate the metrics based on the amount of rounds.
+	for _, team := range result.Teams {
+		for _, player := range team.Players {
+			player.MatchRounds = byte(len(m.Rounds))
+			player.CalculateRatings()
+			player.CalculateAccuracy()
+		}
+	}
 
 	return result
 }
 
 // CreateRoundResult takes a parsed round and returns a persistable RoundResult.
 func CreateRoundResult(r *demoparser.Round) *RoundResult {
-	return &RoundResult{Duration: r.Duration, Kills: make([]*KillResult, len(r.Kills))}
+	return &RoundResult{StartTick: r.StartTick, FreezetimeEndTick: r.FreezetimeEndTick, Duration: r.Duration, Half: r.Half, Overtime: r.Overtime, IsPistolRound: r.IsPistolRound, Kills: make([]*KillResult, len(r.Kills)), Damage: make([]*DamageResult, len(r.Damage)), Clutches: make([]*ClutchResult, len(r.Clutches)),
+		Economy: make([]*EconomyResult, len(r.Economy)), TeamEconomy: make([]*TeamEconomyResult, len(r.Teams)),
+		Utility: make([]*UtilityResult, len(r.Utility)), Flashes: make([]*FlashResult, len(r.Flashes))}
+}
+
+// CreateUtilityResult takes a parsed grenade throw and returns a persistable UtilityResult.
+func CreateUtilityResult(u *demoparser.Utility) *UtilityResult {
+	return &UtilityResult{Tick: u.Tick, PlayerID: u.Thrower.SteamID, Type: u.Type}
+}
+
+// CreateFlashResult takes a parsed flash and returns a persistable FlashResult.
+func CreateFlashResult(f *demoparser.Flash) *FlashResult {
+	return &FlashResult{Tick: f.Tick, AttackerID: f.Attacker.SteamID, VictimID: f.Victim.SteamID, Duration: f.Duration,
+		IsTeamFlash: f.Attacker.Team == f.Victim.Team}
+}
+
+// CreateEconomyResult takes a parsed player economy and returns a persistable EconomyResult.
+func CreateEconomyResult(e *demoparser.Economy) *EconomyResult {
+	return &EconomyResult{PlayerID: e.Player.SteamID, EquipmentValue: e.EquipmentValue, Money: e.Money, MoneySpent: e.MoneySpent}
+}
+
+// CreateTeamEconomyResult takes a parsed team economy and returns a persistable TeamEconomyResult.
+func CreateTeamEconomyResult(e *demoparser.TeamEconomy) *TeamEconomyResult {
+	return &TeamEconomyResult{TeamID: e.Team.StartedAs, EquipmentValue: e.EquipmentValue, Money: e.Money, MoneySpent: e.MoneySpent, BuyType: e.BuyType}
+}
+
+// CreateBombResult takes the parsed bomb events of a round and returns a persistable BombResult.
+func CreateBombResult(b *demoparser.Bomb) *BombResult {
+	bombResult := &BombResult{Site: b.Site, PlantStartTick: b.PlantStartTick, PlantTick: b.PlantTick,
+		DefuseStartTick: b.DefuseStartTick, DefuseTick: b.DefuseTick, HasKit: b.HasKit,
+		IsPlanted: b.IsPlanted, IsDefused: b.IsDefused, IsExploded: b.IsExploded}
+
+	if b.Planter != nil {
+		bombResult.PlanterID = b.Planter.SteamID
+	}
+
+	if b.Defuser != nil {
+		bombResult.DefuserID = b.Defuser.SteamID
+	}
+
+	return bombResult
+}
+
+// CreateDamageResult takes a parsed damage event and returns a persistable DamageResult.
+func CreateDamageResult(d *demoparser.Damage) *DamageResult {
+	damageResult := &DamageResult{Tick: d.Tick, Weapon: d.Weapon, HitGroup: d.HitGroup, HealthDamage: d.HealthDamageTaken,
+		ArmorDamage: d.ArmorDamageTaken}
+
+	if d.Attacker != nil {
+		damageResult.AttackerID = d.Attacker.SteamID
+	}
+
+	if d.Victim != nil {
+		damageResult.VictimID = d.Victim.SteamID
+	}
+
+	return damageResult
+}
+
+// CreateClutchResult takes a parsed clutch and returns a persistable ClutchResult.
+func CreateClutchResult(c *demoparser.Clutch) *ClutchResult {
+	return &ClutchResult{PlayerID: c.Player.SteamID, Opponents: c.Opponents, Won: c.Won}
 }
 
 // CreateKillResult takes a parsed kill and returns a persistable KillResult.
@@ -167,7 +409,7 @@ func CreateKillResult(k *demoparser.Kill) *KillResult {
 	return killResult
 }
 
-func (m *MatchResult) processRounds(rounds []*demoparser.Round) {
+func (m *MatchResult) processRounds(rounds []*demoparser.Round, tradeWindow time.Duration) {
 	for index, round := range rounds {
 		// Create round result and log
 		roundResult := CreateRoundResult(round)
@@ -185,20 +427,121 @@ func (m *MatchResult) processRounds(rounds []*demoparser.Round) {
 		winner := m.getTeam(round.Winner.StartedAs)
 		winner.Wins++
 		roundResult.WinnerTeamID = winner.TeamID
+		roundResult.WinnerSide = round.WinnerSide
+		roundResult.WinReason = round.WinReason
+
+		if round.Bomb != nil {
+			roundResult.Bomb = CreateBombResult(round.Bomb)
+		}
+
+		switch round.WinnerSide {
+		case common.TeamTerrorists:
+			winner.TWins++
+		case common.TeamCounterTerrorists:
+			winner.CTWins++
+		}
 
-		// Pistol round wins.
-		roundNumber := index + 1
-		if roundNumber == 1 || roundNumber == 16 {
+		if round.IsPistolRound {
 			winner.PistolRoundWins++
 		}
 
+		if round.Overtime > m.Overtimes {
+			m.Overtimes = round.Overtime
+		}
+
+		m.getHalf(round.Half, round.Overtime).Wins[demoparser.GetTeamIndex(winner.TeamID, false)]++
+
+		// Count the shots fired per weapon.
+		for _, shot := range round.Shots {
+			player := m.getPlayer(shot.Shooter)
+			if player == nil {
+				continue
+			}
+
+			player.GetWeapon(shot.Weapon.String()).Shots++
+		}
+
+		// Shotgun pellets of one shot hit at the same time and must only count as one hit.
+		lastHits := make(map[*player.WeaponResult]time.Duration)
+
 		// Iterage damage and add that to the damage dealt by each player.
-		for _, damage := range round.Damage {
+		for index, damage := range round.Damage {
+			roundResult.Damage[index] = CreateDamageResult(damage)
+
 			// Attacker might be null according to open issues of the demoparser.
 			attacker := damage.Attacker
 			if attacker != nil {
 				player := m.getPlayer(attacker)
 				player.DamageDealt += damage.HealthDamageTaken
+
+				if damage.Victim == nil || damage.Victim == attacker {
+					continue
+				}
+
+				if demoparser.IsGun(damage.Weapon) {
+					weapon := player.GetWeapon(damage.Weapon.String())
+					if lastHit, found := lastHits[weapon]; !found || lastHit != damage.Tick {
+						weapon.Hits++
+						lastHits[weapon] = damage.Tick
+					}
+
+					if damage.Victim.Team != attacker.Team {
+						weapon.Damage += damage.HealthDamageTaken
+					}
+				}
+
+				// Only count utility damage dealt to enemies.
+				if damage.Victim.Team == attacker.Team {
+					continue
+				}
+
+				switch damage.Weapon {
+				case common.EqHE:
+					player.HEDamage += damage.HealthDamageTaken
+				case common.EqMolotov, common.EqIncendiary:
+					player.FireDamage += damage.HealthDamageTaken
+				}
+			}
+		}
+
+		// Count the thrown grenades per type.
+		for index, utility := range round.Utility {
+			roundResult.Utility[index] = CreateUtilityResult(utility)
+
+			player := m.getPlayer(utility.Thrower)
+			if player == nil {
+				continue
+			}
+
+			switch utility.Type {
+			case common.EqFlash:
+				player.FlashesThrown++
+			case common.EqSmoke:
+				player.SmokesThrown++
+			case common.EqHE:
+				player.HEsThrown++
+			case common.EqMolotov, common.EqIncendiary:
+				player.MolotovsThrown++
+			case common.EqDecoy:
+				player.DecoysThrown++
+			}
+		}
+
+		// Add blinded enemies and team flashes.
+		for index, flash := range round.Flashes {
+			flashResult := CreateFlashResult(flash)
+			roundResult.Flashes[index] = flashResult
+
+			player := m.getPlayer(flash.Attacker)
+			if player == nil {
+				continue
+			}
+
+			if flashResult.IsTeamFlash {
+				player.TeammatesFlashed++
+			} else {
+				player.EnemiesFlashed++
+				player.BlindDuration += flash.Duration
 			}
 		}
 
@@ -219,11 +562,6 @@ func (m *MatchResult) processRounds(rounds []*demoparser.Round) {
 				if kill.Victim != nil {
 					victim := m.getPlayer(kill.Victim)
 					victim.Deaths++
-
-					// First death of each round is an attempted opening duel.
-					if index == 0 {
-						victim.OpeningDuelAttempts++
-					}
 				}
 
 				// Killer may not be set if the player died e.g. through fall damage.
@@ -231,14 +569,12 @@ func (m *MatchResult) processRounds(rounds []*demoparser.Round) {
 					killer := m.getPlayer(kill.Killer)
 					killer.Kills++
 
-					// First kill of each round is an entry kill.
-					if index == 0 {
-						killer.OpeningDuelAttempts++
-						killer.EntryKills++
-					}
+					weapon := killer.GetWeapon(kill.Weapon.String())
+					weapon.Kills++
 
 					if kill.IsHeadshot {
 						killer.Headshots++
+						weapon.Headshots++
 					}
 
 					if _, found := playerKills[killer]; !found {
@@ -251,17 +587,57 @@ func (m *MatchResult) processRounds(rounds []*demoparser.Round) {
 				if kill.Assister != nil {
 					assister := m.getPlayer(kill.Assister)
 					assister.Assists++
+
+					if kill.IsFlashAssist {
+						assister.FlashAssists++
+					}
 				}
 			}
 		}
 
-		// Increase players 3/4/5 Kills per round.
-		for player, kills := range playerKills {
-			if kills <= 2 {
+		// Add clutch attempts and wins to the clutching player.
+		for index, clutch := range round.Clutches {
+			roundResult.Clutches[index] = CreateClutchResult(clutch)
+
+			player := m.getPlayer(clutch.Player)
+			if player == nil {
+				continue
+			}
+
+			player.AddClutch(clutch.Opponents, clutch.Won)
+		}
+
+		// Add the buy of each team and the money spent by each player.
+		buyTypes := make(map[*demoparser.Team]demoparser.BuyType)
+		for index, teamEconomy := range round.Teams {
+			roundResult.TeamEconomy[index] = CreateTeamEconomyResult(teamEconomy)
+			buyTypes[teamEconomy.Team] = teamEconomy.BuyType
+		}
+
+		for index, economy := range round.Economy {
+			roundResult.Economy[index] = CreateEconomyResult(economy)
+
+			player := m.getPlayer(economy.Player)
+			if player == nil {
 				continue
 			}
 
+			player.EquipmentValue += economy.EquipmentValue
+			player.MoneySpent += economy.MoneySpent
+			addBuyRound(player, buyTypes[economy.Player.Team], economy.Player.Team == round.Winner)
+		}
+
+		m.processOpeningDuel(round, roundResult)
+		markTrades(round.Kills, roundResult.Kills, tradeWindow)
+		m.processKAST(round, roundResult)
+
+		// Increase players 1/2/3/4/5 Kills per round.
+		for player, kills := range playerKills {
 			switch kills {
+			case 1:
+				player.RoundsWith1K++
+			case 2:
+				player.RoundsWith2K++
 			case 3:
 				player.RoundsWith3K++
 			case 4:
@@ -273,10 +649,126 @@ func (m *MatchResult) processRounds(rounds []*demoparser.Round) {
 	}
 }
 
+// Adds the opening duel of the round to the killer and victim.
+// The first kill of a player of the other team during the round is the opening duel, thus suicides, team and world kills are skipped.
+func (m *MatchResult) processOpeningDuel(round *demoparser.Round, roundResult *RoundResult) {
+	for _, kill := range round.Kills {
+		if !kill.IsDuringRound || kill.Killer == nil || kill.Victim == nil || kill.Killer.Team == kill.Victim.Team {
+			continue
+		}
+
+		roundResult.OpeningDuel = &OpeningDuelResult{Tick: kill.Tick, KillerID: kill.Killer.SteamID, VictimID: kill.Victim.SteamID,
+			Weapon: kill.Weapon, KillerSide: kill.KillerSide, VictimSide: kill.VictimSide}
+
+		m.getPlayer(kill.Killer).AddOpeningDuel(kill.KillerSide, true)
+		m.getPlayer(kill.Victim).AddOpeningDuel(kill.VictimSide, false)
+		return
+	}
+}
+
+// Marks kills that avenged a teammate within the trade window as trade and the avenged kill as traded.
+// Kill results need to have the same order as the parsed kills.
+func markTrades(kills []*demoparser.Kill, results []*KillResult, tradeWindow time.Duration) {
+	for index, kill := range kills {
+		if kill.Killer == nil || kill.Victim == nil || kill.Killer.Team == kill.Victim.Team {
+			continue
+		}
+
+		for tradeIndex := index + 1; tradeIndex < len(kills); tradeIndex++ {
+			trade := kills[tradeIndex]
+			if trade.Tick-kill.Tick > tradeWindow {
+				break
+			}
+
+			if trade.Victim != kill.Killer || trade.Killer == nil || trade.Killer.Team != kill.Victim.Team {
+				continue
+			}
+
+			results[index].WasTraded = true
+			results[tradeIndex].IsTrade = true
+			break
+		}
+	}
+}
+
+// Adds a KAST round to all players that had a kill, assist, survived or were traded in the round.
+func (m *MatchResult) processKAST(round *demoparser.Round, roundResult *RoundResult) {
+	kast := make(map[uint64]bool)
+	died := make(map[uint64]bool)
+
+	for index, kill := range round.Kills {
+		if kill.Victim != nil {
+			died[kill.Victim.SteamID] = true
+
+			if roundResult.Kills[index].WasTraded {
+				kast[kill.Victim.SteamID] = true
+			}
+		}
+
+		// Suicides and team kills do not count.
+		if kill.Killer != nil && kill.Victim != nil && kill.Killer.Team != kill.Victim.Team {
+			kast[kill.Killer.SteamID] = true
+		}
+
+		if kill.Assister != nil && (kill.Victim == nil || kill.Assister.Team != kill.Victim.Team) {
+			kast[kill.Assister.SteamID] = true
+		}
+	}
+
+	for _, team := range m.Teams {
+		for _, player := range team.Players {
+			if !died[player.SteamID] {
+				kast[player.SteamID] = true
+			}
+
+			if kast[player.SteamID] {
+				player.KASTRounds++
+				roundResult.KASTPlayerIDs = append(roundResult.KASTPlayerIDs, player.SteamID)
+			}
+		}
+	}
+}
+
+// Counts the round and whether it was won for the buy type of the player's team.
+func addBuyRound(p *player.PlayerResult, buyType demoparser.BuyType, won bool) {
+	var rounds, wins *byte
+
+	switch buyType {
+	case demoparser.Eco:
+		rounds, wins = &p.EcoRounds, &p.EcoRoundsWon
+	case demoparser.HalfBuy:
+		rounds, wins = &p.HalfBuyRounds, &p.HalfBuyRoundsWon
+	case demoparser.ForceBuy:
+		rounds, wins = &p.ForceBuyRounds, &p.ForceBuyRoundsWon
+	case demoparser.FullBuy:
+		rounds, wins = &p.FullBuyRounds, &p.FullBuyRoundsWon
+	default:
+		return
+	}
+
+	*rounds++
+	if won {
+		*wins++
+	}
+}
+
 func (m *MatchResult) getTeam(team common.Team) *TeamResult {
 	return m.Teams[demoparser.GetTeamIndex(team, false)]
 }
 
+// Returns the result of the half and adds it if it does not exist yet.
+func (m *MatchResult) getHalf(half byte, overtime byte) *HalfResult {
+	for _, halfResult := range m.Halves {
+		if halfResult.Half == half {
+			return halfResult
+		}
+	}
+
+	halfResult := &HalfResult{Half: half, Overtime: overtime, Wins: make([]byte, 2)}
+	m.Halves = append(m.Halves, halfResult)
+	return halfResult
+}
+
 func (m *MatchResult) getPlayer(player *demoparser.Player) *player.PlayerResult {
 	if player == nil {
 		return nil
@@ -0,0 +1,316 @@
+package match_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Cludch/csgo-tools/internal/demoparser"
+	"github.com/Cludch/csgo-tools/internal/domain/entity"
+	"github.com/Cludch/csgo-tools/internal/domain/match"
+	"github.com/markus-wa/demoinfocs-golang/v2/pkg/demoinfocs/common"
+	"github.com/markus-wa/demoinfocs-golang/v2/pkg/demoinfocs/events"
+	"github.com/stretchr/testify/assert"
+)
+
+// Creates a match with two players per team.
+func newMatchData() *demoparser.MatchData {
+	t := &demoparser.Team{StartedAs: common.TeamTerrorists}
+	ct := &demoparser.Team{StartedAs: common.TeamCounterTerrorists}
+
+	players := []*demoparser.Player{
+		{SteamID: 1, Name: "t1", Team: t},
+		{SteamID: 2, Name: "t2", Team: t},
+		{SteamID: 3, Name: "ct1", Team: ct},
+		{SteamID: 4, Name: "ct2", Team: ct},
+	}
+	t.Players = players[:2]
+	ct.Players = players[2:]
+
+	return &demoparser.MatchData{ID: entity.NewID(), Map: "de_dust2", Players: players, Teams: [2]*demoparser.Team{t, ct}}
+}
+
+func TestCreateResultTrades(t *testing.T) {
+	m := newMatchData()
+	p := m.Players
+	m.Rounds = []*demoparser.Round{{
+		Winner: m.Teams[0],
+		Kills: []*demoparser.Kill{
+			{Tick: time.Second, Killer: p[2], Victim: p[0]},
+			{Tick: 3 * time.Second, Killer: p[1], Victim: p[2]},
+			{Tick: 20 * time.Second, Killer: p[3], Victim: p[1]},
+		},
+	}}
+
+	result := match.CreateResult(m, match.DefaultTradeWindow)
+	kills := result.Rounds[0].Kills
+
+	assert.True(t, kills[0].WasTraded)
+	assert.True(t, kills[1].IsTrade)
+	assert.False(t, kills[1].WasTraded)
+	assert.False(t, kills[2].IsTrade)
+
+	// t1 was traded, t2 and ct2 got a kill, ct1 got a kill.
+	assert.ElementsMatch(t, []uint64{1, 2, 3, 4}, result.Rounds[0].KASTPlayerIDs)
+	assert.Equal(t, float32(1), result.Teams[0].Players[0].KAST)
+}
+
+func TestCreateResultKASTWithoutTrade(t *testing.T) {
+	m := newMatchData()
+	p := m.Players
+	m.Rounds = []*demoparser.Round{{
+		Winner: m.Teams[1],
+		Kills: []*demoparser.Kill{
+			{Tick: time.Second, Killer: p[2], Victim: p[0]},
+			{Tick: 10 * time.Second, Killer: p[1], Victim: p[2]},
+		},
+	}}
+
+	result := match.CreateResult(m, match.DefaultTradeWindow)
+
+	assert.False(t, result.Rounds[0].Kills[0].WasTraded)
+	assert.ElementsMatch(t, []uint64{2, 3, 4}, result.Rounds[0].KASTPlayerIDs)
+	assert.Equal(t, float32(0), result.Teams[0].Players[0].KAST)
+}
+
+func TestCreateResultWeapons(t *testing.T) {
+	m := newMatchData()
+	p := m.Players
+	m.Rounds = []*demoparser.Round{{
+		Winner: m.Teams[0],
+		Shots: []*demoparser.Shot{
+			{Tick: time.Second, Shooter: p[0], Weapon: common.EqNova},
+			{Tick: 2 * time.Second, Shooter: p[0], Weapon: common.EqNova},
+		},
+		Damage: []*demoparser.Damage{
+			// Two pellets of the same shot.
+			{Tick: time.Second, Attacker: p[0], Victim: p[2], Weapon: common.EqNova, HealthDamageTaken: 20},
+			{Tick: time.Second, Attacker: p[0], Victim: p[2], Weapon: common.EqNova, HealthDamageTaken: 20},
+			{Tick: 2 * time.Second, Attacker: p[0], Victim: p[2], Weapon: common.EqNova, HealthDamageTaken: 60},
+		},
+		Kills: []*demoparser.Kill{
+			{Tick: 2 * time.Second, Killer: p[0], Victim: p[2], Weapon: common.EqNova, IsHeadshot: true},
+		},
+	}}
+
+	result := match.CreateResult(m, match.DefaultTradeWindow)
+	weapons := result.Teams[0].Players[0].Weapons
+
+	assert.Len(t, weapons, 1)
+	assert.Equal(t, "Nova", weapons[0].Weapon)
+	assert.Equal(t, 2, weapons[0].Shots)
+	assert.Equal(t, 2, weapons[0].Hits)
+	assert.Equal(t, 100, weapons[0].Damage)
+	assert.Equal(t, 1, weapons[0].Kills)
+	assert.Equal(t, 1, weapons[0].Headshots)
+	assert.Equal(t, float32(1), weapons[0].Accuracy)
+}
+
+func TestCreateResultUtility(t *testing.T) {
+	m := newMatchData()
+	p := m.Players
+	m.Rounds = []*demoparser.Round{{
+		Winner: m.Teams[0],
+		Utility: []*demoparser.Utility{
+			{Tick: time.Second, Thrower: p[0], Type: common.EqFlash},
+			{Tick: time.Second, Thrower: p[0], Type: common.EqSmoke},
+			{Tick: 2 * time.Second, Thrower: p[0], Type: common.EqHE},
+			{Tick: 3 * time.Second, Thrower: p[0], Type: common.EqIncendiary},
+		},
+		Flashes: []*demoparser.Flash{
+			{Tick: 2 * time.Second, Attacker: p[0], Victim: p[2], Duration: 2 * time.Second},
+			{Tick: 2 * time.Second, Attacker: p[0], Victim: p[1], Duration: time.Second},
+		},
+		Damage: []*demoparser.Damage{
+			{Tick: 3 * time.Second, Attacker: p[0], Victim: p[2], Weapon: common.EqHE, HealthDamageTaken: 40},
+			// Team damage does not count.
+			{Tick: 3 * time.Second, Attacker: p[0], Victim: p[1], Weapon: common.EqHE, HealthDamageTaken: 20},
+			{Tick: 4 * time.Second, Attacker: p[0], Victim: p[2], Weapon: common.EqIncendiary, HealthDamageTaken: 15},
+		},
+		Kills: []*demoparser.Kill{
+			{Tick: 5 * time.Second, Killer: p[1], Victim: p[2], Assister: p[0], Weapon: common.EqAK47, IsFlashAssist: true},
+		},
+	}}
+
+	result := match.CreateResult(m, match.DefaultTradeWindow)
+	player := result.Teams[0].Players[0]
+
+	assert.Equal(t, byte(1), player.FlashesThrown)
+	assert.Equal(t, byte(1), player.SmokesThrown)
+	assert.Equal(t, byte(1), player.HEsThrown)
+	assert.Equal(t, byte(1), player.MolotovsThrown)
+	assert.Equal(t, byte(0), player.DecoysThrown)
+	assert.Equal(t, byte(1), player.EnemiesFlashed)
+	assert.Equal(t, byte(1), player.TeammatesFlashed)
+	assert.Equal(t, 2*time.Second, player.BlindDuration)
+	assert.Equal(t, byte(1), player.FlashAssists)
+	assert.Equal(t, 40, player.HEDamage)
+	assert.Equal(t, 15, player.FireDamage)
+}
+
+func TestNewMapSiteStats(t *testing.T) {
+	m := &match.Match{Result: &match.MatchResult{Rounds: []*match.RoundResult{
+		{Bomb: &match.BombResult{Site: "A", IsPlanted: true, IsExploded: true}, WinnerSide: common.TeamTerrorists},
+		{Bomb: &match.BombResult{Site: "A", IsPlanted: true, IsDefused: true, HasKit: true}, WinnerSide: common.TeamCounterTerrorists},
+		{Bomb: &match.BombResult{Site: "B", IsPlanted: true, IsExploded: true}, WinnerSide: common.TeamTerrorists},
+		{WinnerSide: common.TeamCounterTerrorists},
+	}}}
+
+	stats := match.NewMapSiteStats("de_dust2", []*match.Match{m})
+
+	assert.Equal(t, 4, stats.Rounds)
+	assert.Equal(t, 3, stats.Plants)
+	assert.Len(t, stats.Sites, 2)
+
+	a := stats.Sites[0]
+	assert.Equal(t, "A", a.Site)
+	assert.Equal(t, 2, a.Plants)
+	assert.InDelta(t, 0.67, a.PlantRate, 0.01)
+	assert.Equal(t, 1, a.PostPlantWins)
+	assert.Equal(t, 1, a.Retakes)
+	assert.Equal(t, 1, a.KitDefuses)
+	assert.Equal(t, float32(0.5), a.RetakeRate)
+
+	b := stats.Sites[1]
+	assert.Equal(t, "B", b.Site)
+	assert.Equal(t, float32(1), b.PostPlantWinRate)
+}
+
+func TestNewMatchDamage(t *testing.T) {
+	m := newMatchData()
+	p := m.Players
+	m.Rounds = []*demoparser.Round{{
+		Winner: m.Teams[1],
+		Damage: []*demoparser.Damage{
+			{Tick: time.Second, Attacker: p[0], Victim: p[2], Weapon: common.EqAK47, HitGroup: events.HitGroupHead, HealthDamageTaken: 50, ArmorDamageTaken: 10},
+			{Tick: 2 * time.Second, Attacker: p[0], Victim: p[3], Weapon: common.EqHE, HealthDamageTaken: 30},
+			{Tick: 3 * time.Second, Attacker: p[0], Victim: p[1], Weapon: common.EqMolotov, HealthDamageTaken: 8},
+			{Tick: 4 * time.Second, Attacker: p[2], Victim: p[0], Weapon: common.EqM4A4, HitGroup: events.HitGroupChest, HealthDamageTaken: 100},
+			{Tick: 5 * time.Second, Attacker: p[0], Victim: p[2], Weapon: common.EqMolotov, HealthDamageTaken: 20},
+		},
+		Kills: []*demoparser.Kill{
+			{Tick: 4 * time.Second, Killer: p[2], Victim: p[0], Weapon: common.EqM4A4},
+		},
+	}}
+
+	result := &match.Match{ID: m.ID, Result: match.CreateResult(m, match.DefaultTradeWindow)}
+	damage := match.NewMatchDamage(result)
+
+	t1 := damage.Players[0]
+	assert.Equal(t, uint64(1), t1.SteamID)
+	assert.Equal(t, 100, t1.Damage)
+	assert.Equal(t, 50, t1.GunDamage)
+	assert.Equal(t, 50, t1.UtilityDamage)
+	assert.Equal(t, 10, t1.ArmorDamage)
+	assert.Equal(t, 50, t1.HitGroups["head"])
+	assert.Equal(t, []int{80}, t1.DamageBeforeDeath)
+
+	// Victims are the rows, attackers the columns.
+	assert.Equal(t, []int{0, 0, 100, 0}, damage.Matrix[0])
+	assert.Equal(t, 8, damage.Matrix[1][0])
+	assert.Equal(t, 70, damage.Matrix[2][0])
+	assert.Equal(t, 30, damage.Matrix[3][0])
+}
+
+func TestCreateResultOpeningDuelSkipsSuicides(t *testing.T) {
+	m := newMatchData()
+	p := m.Players
+	m.Rounds = []*demoparser.Round{{
+		Winner: m.Teams[0],
+		Kills: []*demoparser.Kill{
+			{Tick: time.Second, Victim: p[3], IsDuringRound: true},
+			{Tick: 2 * time.Second, Killer: p[0], Victim: p[0], IsDuringRound: true},
+			{Tick: 3 * time.Second, Killer: p[1], Victim: p[2], Weapon: common.EqAK47, IsDuringRound: true,
+				KillerSide: common.TeamTerrorists, VictimSide: common.TeamCounterTerrorists},
+		},
+	}}
+
+	result := match.CreateResult(m, match.DefaultTradeWindow)
+	duel := result.Rounds[0].OpeningDuel
+
+	assert.Equal(t, uint64(2), duel.KillerID)
+	assert.Equal(t, uint64(3), duel.VictimID)
+
+	t2 := result.Teams[0].Players[1]
+	assert.Equal(t, byte(1), t2.EntryKillsAsT)
+	assert.Equal(t, byte(1), t2.OpeningDuelAttemptsAsT)
+	assert.Equal(t, byte(0), result.Teams[1].Players[1].OpeningDuelAttempts)
+	assert.Equal(t, byte(1), result.Teams[1].Players[0].OpeningDuelAttemptsAsCT)
+
+	duels := match.NewMatchOpeningDuels(&match.Match{Result: result})
+	assert.Equal(t, 1, duels.Matrix[1][2])
+	assert.Equal(t, float32(0), duels.Players[2].EntrySuccessRateAsCT)
+	assert.Equal(t, float32(1), duels.Players[1].EntrySuccessRate)
+}
+
+func TestCreateResultHalves(t *testing.T) {
+	m := newMatchData()
+	tTeam, ctTeam := m.Teams[0], m.Teams[1]
+	m.Rounds = []*demoparser.Round{
+		{Half: 1, IsPistolRound: true, Winner: tTeam, WinnerSide: common.TeamTerrorists},
+		{Half: 1, Winner: ctTeam, WinnerSide: common.TeamCounterTerrorists},
+		{Half: 2, IsPistolRound: true, Winner: ctTeam, WinnerSide: common.TeamTerrorists},
+		{Half: 3, Overtime: 1, Winner: tTeam, WinnerSide: common.TeamCounterTerrorists},
+	}
+
+	result := match.CreateResult(m, match.DefaultTradeWindow)
+
+	assert.Len(t, result.Halves, 3)
+	assert.Equal(t, []byte{1, 1}, result.Halves[0].Wins)
+	assert.Equal(t, []byte{0, 1}, result.Halves[1].Wins)
+	assert.Equal(t, byte(1), result.Halves[2].Overtime)
+
+	assert.Equal(t, byte(1), result.Teams[0].TWins)
+	assert.Equal(t, byte(1), result.Teams[0].CTWins)
+	assert.Equal(t, byte(1), result.Teams[0].PistolRoundWins)
+	assert.Equal(t, byte(1), result.Teams[1].TWins)
+	assert.Equal(t, byte(1), result.Teams[1].PistolRoundWins)
+}
+
+func TestCreateResultWingman(t *testing.T) {
+	m := newMatchData()
+	m.Format, m.MaxRounds = demoparser.Wingman, 16
+	m.Rounds = []*demoparser.Round{
+		{Half: 1, IsPistolRound: true, Winner: m.Teams[0], WinnerSide: common.TeamTerrorists},
+		{Half: 2, IsPistolRound: true, Winner: m.Teams[0], WinnerSide: common.TeamCounterTerrorists},
+	}
+
+	result := match.CreateResult(m, match.DefaultTradeWindow)
+
+	assert.Equal(t, demoparser.Wingman, result.Format)
+	assert.Equal(t, 16, result.MaxRounds)
+	assert.Equal(t, byte(0), result.Overtimes)
+	assert.Len(t, result.Teams[0].Players, 2)
+	assert.Equal(t, byte(2), result.Teams[0].PistolRoundWins)
+}
+
+func TestNewRoundTimeline(t *testing.T) {
+	m := newMatchData()
+	p := m.Players
+	m.Rounds = []*demoparser.Round{{
+		StartTick: 100 * time.Second, FreezetimeEndTick: 115 * time.Second, Duration: 60 * time.Second,
+		Winner: m.Teams[0], WinnerSide: common.TeamTerrorists, WinReason: events.RoundEndReasonTargetBombed,
+		Utility: []*demoparser.Utility{{Tick: 120 * time.Second, Thrower: p[0], Type: common.EqSmoke}},
+		Damage: []*demoparser.Damage{
+			{Tick: 125 * time.Second, Attacker: p[0], Victim: p[1], Weapon: common.EqAK47},
+			{Tick: 130 * time.Second, Attacker: p[2], Victim: p[0], Weapon: common.EqM4A4},
+		},
+		Kills: []*demoparser.Kill{{Tick: 131 * time.Second, Killer: p[2], Victim: p[0], Weapon: common.EqM4A4}},
+		Bomb:  &demoparser.Bomb{Site: "A", Planter: p[1], PlantTick: 140 * time.Second, IsPlanted: true, IsExploded: true},
+	}}
+
+	result := &match.Match{Result: match.CreateResult(m, match.DefaultTradeWindow)}
+
+	assert.Nil(t, match.NewRoundTimeline(result, 2))
+
+	timeline := match.NewRoundTimeline(result, 1)
+	types := make([]match.TimelineEventType, len(timeline.Events))
+	for i, event := range timeline.Events {
+		types[i] = event.Type
+	}
+
+	assert.Equal(t, []match.TimelineEventType{match.FreezetimeEnd, match.UtilityThrown, match.FirstContact, match.Kill, match.BombPlant, match.RoundEnd}, types)
+	assert.Equal(t, float64(15), timeline.Events[0].Time)
+	assert.Equal(t, uint64(3), timeline.Events[2].PlayerID)
+	assert.Equal(t, "A", timeline.Events[4].Site)
+	assert.Equal(t, float64(60), timeline.Events[5].Time)
+}
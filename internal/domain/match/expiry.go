@@ -0,0 +1,28 @@
+package match
+
+import "time"
+
+// ExpiredBefore returns the time before which matchmaking demos are expired. Valve deletes the demos after roughly
+// a month.
+func ExpiredBefore(now time.Time, expiryDays int) time.Time {
+	return now.Add(-time.Duration(expiryDays) * 24 * time.Hour)
+}
+
+// PlayedAt returns the time the match has been played. Matches whose details have not been received yet fall back to
+// the time they have been created, which is when their share code has been received.
+func (m *Match) PlayedAt() time.Time {
+	if m.Time.IsZero() {
+		return m.CreatedAt
+	}
+
+	return m.Time
+}
+
+// IsExpired returns whether the demo of a matchmaking match has been deleted by Valve before it was downloaded.
+func (m *Match) IsExpired(expiredBefore time.Time) bool {
+	if m.Source != MatchMaking || (m.Status != Created && m.Status != Downloadable) {
+		return false
+	}
+
+	return m.PlayedAt().Before(expiredBefore)
+}
@@ -0,0 +1,38 @@
+package match_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Cludch/csgo-tools/internal/domain/match"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsExpired(t *testing.T) {
+	now := time.Date(2021, 12, 1, 12, 0, 0, 0, time.UTC)
+	expiredBefore := match.ExpiredBefore(now, 30)
+	assert.Equal(t, time.Date(2021, 11, 1, 12, 0, 0, 0, time.UTC), expiredBefore)
+
+	m, _ := match.NewMatch(match.MatchMaking)
+	m.CreatedAt = now.Add(-40 * 24 * time.Hour)
+
+	// Matches without details fall back to the time the share code has been received.
+	assert.True(t, m.IsExpired(expiredBefore))
+
+	m.Time = now.Add(-10 * 24 * time.Hour)
+	assert.Equal(t, m.Time, m.PlayedAt())
+	assert.False(t, m.IsExpired(expiredBefore))
+
+	m.Time = now.Add(-31 * 24 * time.Hour)
+	_ = m.SetStatus(match.Downloadable, "")
+	assert.True(t, m.IsExpired(expiredBefore))
+
+	// Downloaded demos are kept.
+	_ = m.SetStatus(match.Downloaded, "")
+	assert.False(t, m.IsExpired(expiredBefore))
+
+	faceit, _ := match.NewMatch(match.Faceit)
+	faceit.Time = m.Time
+	_ = faceit.SetStatus(match.Downloadable, "")
+	assert.False(t, faceit.IsExpired(expiredBefore))
+}
@@ -14,8 +14,6 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-var ctx = context.TODO()
-
 type RepositoryMongo struct {
 	db *entity.Service
 }
@@ -54,78 +52,178 @@ func (r *RepositoryMongo) createIndex() {
 	}
 }
 
-func (r *RepositoryMongo) Create(m *Match) error {
+func (r *RepositoryMongo) Create(ctx context.Context, m *Match) error {
+	recordActor(ctx, m)
+
 	collection := r.getCollection()
-	_, err := collection.InsertOne(ctx, m)
-	return handleError(err)
+	if _, err := collection.InsertOne(ctx, m); err != nil {
+		return handleError(err)
+	}
+
+	m.statusChanges = nil
+	return nil
 }
 
-func (r *RepositoryMongo) Find(id entity.ID) (*Match, error) {
+func (r *RepositoryMongo) Find(ctx context.Context, id entity.ID) (*Match, error) {
 	filterConfig := bson.M{"_id": id}
-	m, err := r.filterOne(filterConfig)
+	m, err := r.filterOne(ctx, filterConfig)
 	return m, handleError(err)
 }
 
-func (r *RepositoryMongo) FindByFilename(filename string) (*Match, error) {
-	filterConfig := bson.M{"filename": filename}
-	m, err := r.filterOne(filterConfig)
+// FindByFilename returns the match of the demo file, which may also be a duplicate of its demo.
+func (r *RepositoryMongo) FindByFilename(ctx context.Context, filename string) (*Match, error) {
+	filterConfig := bson.M{"$or": []bson.M{
+		{"filename": filename},
+		{"duplicateFiles": filename},
+	}}
+	m, err := r.filterOne(ctx, filterConfig)
 	return m, handleError(err)
 }
 
-func (r *RepositoryMongo) FindByFaceitId(id string) (*Match, error) {
+func (r *RepositoryMongo) FindByFaceitId(ctx context.Context, id string) (*Match, error) {
 	filterConfig := bson.M{"faceitMatchId": id}
-	m, err := r.filterOne(filterConfig)
+	m, err := r.filterOne(ctx, filterConfig)
 	return m, handleError(err)
 }
 
-func (r *RepositoryMongo) FindByValveId(id uint64) (*Match, error) {
+func (r *RepositoryMongo) FindByValveId(ctx context.Context, id uint64) (*Match, error) {
 	filterConfig := bson.M{"shareCode.matchId": id}
-	m, err := r.filterOne(filterConfig)
+	m, err := r.filterOne(ctx, filterConfig)
 	return m, handleError(err)
 }
 
-func (r *RepositoryMongo) FindByValveOutcomeId(id uint64) (*Match, error) {
+func (r *RepositoryMongo) FindByValveOutcomeId(ctx context.Context, id uint64) (*Match, error) {
 	filterConfig := bson.D{{Key: "shareCode.outcomeId", Value: id}}
-	m, err := r.filterOne(filterConfig)
+	m, err := r.filterOne(ctx, filterConfig)
 	return m, handleError(err)
 }
 
-func (r *RepositoryMongo) List() ([]*Match, error) {
+func (r *RepositoryMongo) List(ctx context.Context) ([]*Match, error) {
 	filterConfig := bson.M{}
-	m, err := r.filter(filterConfig)
+	m, err := r.filter(ctx, filterConfig)
 	return m, handleError(err)
 }
 
-func (r *RepositoryMongo) ListDownloadedMatches() ([]*Match, error) {
+func (r *RepositoryMongo) ListDownloadedMatches(ctx context.Context) ([]*Match, error) {
 	filterConfig := bson.M{"status": Downloaded}
-	m, err := r.filter(filterConfig)
+	m, err := r.filter(ctx, filterConfig)
 	return m, handleError(err)
 }
 
-func (r *RepositoryMongo) ListDownloadableMatches() ([]*Match, error) {
+// ListDownloadableMatches returns the matches to download, skipping matchmaking matches played before expiredBefore.
+func (r *RepositoryMongo) ListDownloadableMatches(ctx context.Context, expiredBefore time.Time) ([]*Match, error) {
 	filterConfig := bson.M{"status": Downloadable}
-	m, err := r.filter(filterConfig)
+	for k, v := range notExpired(expiredBefore) {
+		filterConfig[k] = v
+	}
+	m, err := r.filter(ctx, filterConfig)
 	return m, handleError(err)
 }
 
-func (r *RepositoryMongo) ListParsedMatches() ([]*Match, error) {
+func (r *RepositoryMongo) ListParsedMatches(ctx context.Context) ([]*Match, error) {
 	filterConfig := bson.M{"status": Parsed}
-	m, err := r.filter(filterConfig)
+	m, err := r.filter(ctx, filterConfig)
+	return m, handleError(err)
+}
+
+func (r *RepositoryMongo) ListParsedMatchesByMap(ctx context.Context, mapName string) ([]*Match, error) {
+	filterConfig := bson.M{"status": Parsed, "result.map": mapName}
+	m, err := r.filter(ctx, filterConfig)
 	return m, handleError(err)
 }
 
-func (r *RepositoryMongo) ListValveMatchesMissingDownloadUrl() ([]*Match, error) {
+// ListValveMatchesMissingDownloadUrl returns the matchmaking matches missing their download url, skipping the matches
+// played before expiredBefore.
+func (r *RepositoryMongo) ListValveMatchesMissingDownloadUrl(ctx context.Context, expiredBefore time.Time) ([]*Match, error) {
 	filterConfig := bson.M{
 		"$and": []bson.M{
 			{"source": MatchMaking},
 			{"status": Created},
+			notExpired(expiredBefore),
 		},
 	}
-	m, err := r.filter(filterConfig)
+	m, err := r.filter(ctx, filterConfig)
 	return m, handleError(err)
 }
 
-func (r *RepositoryMongo) Delete(id entity.ID) error {
+// ListExpiredMatches returns the matchmaking matches played before expiredBefore which have not been downloaded.
+func (r *RepositoryMongo) ListExpiredMatches(ctx context.Context, expiredBefore time.Time) ([]*Match, error) {
+	filterConfig := bson.M{
+		"source": MatchMaking,
+		"status": bson.M{"$in": []Status{Created, Downloadable}},
+		"$or": []bson.M{
+			{"time": bson.M{"$lt": expiredBefore}},
+			{"time": bson.M{"$exists": false}, "createdAt": bson.M{"$lt": expiredBefore}},
+		},
+	}
+	m, err := r.filter(ctx, filterConfig)
+	return m, handleError(err)
+}
+
+func (r *RepositoryMongo) CountByStatus(ctx context.Context, status Status) (int64, error) {
+	filterConfig := bson.M{"status": status}
+	count, err := r.getCollection().CountDocuments(ctx, filterConfig)
+	return count, handleError(err)
+}
+
+// Filters matches that are either no matchmaking matches or have been played after expiredBefore. Matches without a
+// time fall back to the time they have been created, which is when their share code has been received.
+func notExpired(expiredBefore time.Time) bson.M {
+	return bson.M{"$or": []bson.M{
+		{"source": bson.M{"$ne": MatchMaking}},
+		{"time": bson.M{"$gte": expiredBefore}},
+		{"time": bson.M{"$exists": false}, "createdAt": bson.M{"$gte": expiredBefore}},
+	}}
+}
+
+// FindDuplicates returns the other matches whose demo has the same checksum or header as the demo of the match.
+func (r *RepositoryMongo) FindDuplicates(ctx context.Context, m *Match) ([]*Match, error) {
+	f := m.Fingerprint
+	filterConfig := bson.M{
+		"_id": bson.M{"$ne": m.ID},
+		"$or": []bson.M{
+			{"fingerprint.checksum": f.Checksum},
+			{
+				"fingerprint.serverName":    f.ServerName,
+				"fingerprint.mapName":       f.MapName,
+				"fingerprint.playbackTicks": f.PlaybackTicks,
+			},
+		},
+	}
+	matches, err := r.filter(ctx, filterConfig)
+	return matches, handleError(err)
+}
+
+// Replace replaces the match if it still has the status it was loaded with. Returns a *TransitionError otherwise.
+func (r *RepositoryMongo) Replace(ctx context.Context, m *Match) error {
+	filter := bson.M{"_id": m.ID, "status": m.savedStatus()}
+
+	recordActor(ctx, m)
+	res, err := r.getCollection().ReplaceOne(ctx, filter, m)
+	if err != nil {
+		return handleError(err)
+	}
+
+	if res.MatchedCount == 0 {
+		return r.transitionError(ctx, m)
+	}
+
+	m.statusChanges = nil
+	return nil
+}
+
+func (r *RepositoryMongo) UpdateFingerprint(ctx context.Context, m *Match) error {
+	filter := bson.M{"_id": m.ID}
+
+	update := bson.D{primitive.E{Key: "$set", Value: bson.D{
+		primitive.E{Key: "fingerprint", Value: m.Fingerprint},
+	}}}
+
+	t := &Match{}
+	return handleError(r.getCollection().FindOneAndUpdate(ctx, filter, update).Decode(t))
+}
+
+func (r *RepositoryMongo) Delete(ctx context.Context, id entity.ID) error {
 	filter := bson.M{"_id": id}
 
 	res, err := r.getCollection().DeleteOne(ctx, filter)
@@ -140,7 +238,7 @@ func (r *RepositoryMongo) Delete(id entity.ID) error {
 	return nil
 }
 
-func (r *RepositoryMongo) UpdateResult(m *Match) error {
+func (r *RepositoryMongo) UpdateResult(ctx context.Context, m *Match) error {
 	filter := bson.M{"_id": m.ID}
 
 	update := bson.D{primitive.E{Key: "$set", Value: bson.D{
@@ -151,19 +249,16 @@ func (r *RepositoryMongo) UpdateResult(m *Match) error {
 	return handleError(r.getCollection().FindOneAndUpdate(ctx, filter, update).Decode(t))
 }
 
-func (r *RepositoryMongo) UpdateStatus(m *Match) error {
-	filter := bson.M{"_id": m.ID}
-
+func (r *RepositoryMongo) UpdateStatus(ctx context.Context, m *Match) error {
 	update := bson.D{primitive.E{Key: "$set", Value: bson.D{
 		primitive.E{Key: "status", Value: m.Status},
+		primitive.E{Key: "lastError", Value: m.LastError},
 	}}}
 
-	t := &Match{}
-	return handleError(r.getCollection().FindOneAndUpdate(ctx, filter, update).Decode(t))
+	return r.updateWithStatusChanges(ctx, m, update)
 }
 
-func (r *RepositoryMongo) UpdateStatusAndFilename(m *Match) error {
-	filter := bson.M{"_id": m.ID}
+func (r *RepositoryMongo) UpdateStatusAndFilename(ctx context.Context, m *Match) error {
 	var update primitive.D
 
 	if m.Filename != "" {
@@ -177,28 +272,108 @@ func (r *RepositoryMongo) UpdateStatusAndFilename(m *Match) error {
 		}}}
 	}
 
+	return r.updateWithStatusChanges(ctx, m, update)
+}
+
+func (r *RepositoryMongo) UpdateFileState(ctx context.Context, m *Match) error {
+	filter := bson.M{"_id": m.ID}
+
+	update := bson.D{primitive.E{Key: "$set", Value: bson.D{
+		primitive.E{Key: "fileState", Value: m.FileState},
+		primitive.E{Key: "filename", Value: m.Filename},
+	}}}
+
 	t := &Match{}
 	return handleError(r.getCollection().FindOneAndUpdate(ctx, filter, update).Decode(t))
 }
 
-func (r *RepositoryMongo) UpdateDownloadInformation(m *Match) error {
-	filter := bson.M{"_id": m.ID}
+func (r *RepositoryMongo) UpdateDownload(ctx context.Context, m *Match) error {
+	update := bson.D{primitive.E{Key: "$set", Value: bson.D{
+		primitive.E{Key: "status", Value: m.Status},
+		primitive.E{Key: "filename", Value: m.Filename},
+		primitive.E{Key: "fileState", Value: m.FileState},
+		primitive.E{Key: "download", Value: m.Download},
+		primitive.E{Key: "fingerprint", Value: m.Fingerprint},
+		primitive.E{Key: "lastError", Value: m.LastError},
+	}}}
+
+	return r.updateWithStatusChanges(ctx, m, update)
+}
+
+func (r *RepositoryMongo) UpdateDownloadAttempts(ctx context.Context, m *Match) error {
+	update := bson.D{primitive.E{Key: "$set", Value: bson.D{
+		primitive.E{Key: "status", Value: m.Status},
+		primitive.E{Key: "downloadAttempts", Value: m.DownloadAttempts},
+		primitive.E{Key: "maxDownloadAttempts", Value: m.MaxDownloadAttempts},
+		primitive.E{Key: "lastError", Value: m.LastError},
+	}}}
+
+	return r.updateWithStatusChanges(ctx, m, update)
+}
 
+func (r *RepositoryMongo) UpdateDownloadInformation(ctx context.Context, m *Match) error {
 	update := bson.D{primitive.E{Key: "$set", Value: bson.D{
 		primitive.E{Key: "status", Value: m.Status},
 		primitive.E{Key: "time", Value: m.Time},
 		primitive.E{Key: "url", Value: m.DownloadURL},
 	}}}
 
-	t := &Match{}
-	return handleError(r.getCollection().FindOneAndUpdate(ctx, filter, update).Decode(t))
+	return r.updateWithStatusChanges(ctx, m, update)
+}
+
+// Updates the match and appends its unsaved status changes to the status history. The changes are pushed instead of
+// replacing the history, thus the changes of concurrent writers are all kept.
+// The match is only updated if it still has the status it was loaded with. Returns a *TransitionError otherwise, as
+// the status has been changed by another worker in the meantime.
+func (r *RepositoryMongo) updateWithStatusChanges(ctx context.Context, m *Match, update bson.D) error {
+	filter := bson.M{"_id": m.ID, "status": m.savedStatus()}
+
+	recordActor(ctx, m)
+	if len(m.statusChanges) > 0 {
+		update = append(update, primitive.E{Key: "$push", Value: bson.D{
+			primitive.E{Key: "statusHistory", Value: bson.D{primitive.E{Key: "$each", Value: m.statusChanges}}},
+		}})
+	}
+
+	res, err := r.getCollection().UpdateOne(ctx, filter, update)
+	if err != nil {
+		return handleError(err)
+	}
+
+	if res.MatchedCount == 0 {
+		return r.transitionError(ctx, m)
+	}
+
+	m.statusChanges = nil
+	return nil
+}
+
+// Returns a *TransitionError from the status the match has in the database or entity.ErrNotFound if it has been
+// deleted.
+func (r *RepositoryMongo) transitionError(ctx context.Context, m *Match) error {
+	current, err := r.Find(ctx, m.ID)
+	if err != nil {
+		return err
+	}
+
+	return &TransitionError{From: current.Status, To: m.Status}
+}
+
+// Records the actor of the context as actor of the unsaved status changes.
+func recordActor(ctx context.Context, m *Match) {
+	actor := ActorFrom(ctx)
+	for _, change := range m.statusChanges {
+		if change.Actor == "" {
+			change.Actor = actor
+		}
+	}
 }
 
 func (r *RepositoryMongo) getCollection() *mongo.Collection {
 	return r.db.GetCollection("matches")
 }
 
-func (r *RepositoryMongo) filterOne(filter interface{}) (*Match, error) {
+func (r *RepositoryMongo) filterOne(ctx context.Context, filter interface{}) (*Match, error) {
 	var m *Match
 	res := r.getCollection().FindOne(ctx, filter)
 	if err := res.Decode(&m); err != nil {
@@ -208,7 +383,7 @@ func (r *RepositoryMongo) filterOne(filter interface{}) (*Match, error) {
 	return m, nil
 }
 
-func (r *RepositoryMongo) filter(filter interface{}) ([]*Match, error) {
+func (r *RepositoryMongo) filter(ctx context.Context, filter interface{}) ([]*Match, error) {
 	var matches []*Match
 
 	cur, err := r.getCollection().Find(ctx, filter)
@@ -1,53 +1,72 @@
 package match
 
 import (
+	"context"
 	"time"
 
 	"github.com/Cludch/csgo-tools/internal/domain/entity"
+	"github.com/Cludch/csgo-tools/pkg/demo"
 	"github.com/Cludch/csgo-tools/pkg/share_code"
 )
 
 type Repository interface {
-	Create(*Match) error
-
-	Find(entity.ID) (*Match, error)
-	FindByFilename(filename string) (*Match, error)
-	FindByFaceitId(string) (*Match, error)
-	FindByValveId(uint64) (*Match, error)
-	FindByValveOutcomeId(uint64) (*Match, error)
-
-	List() ([]*Match, error)
-	ListDownloadedMatches() ([]*Match, error)
-	ListDownloadableMatches() ([]*Match, error)
-	ListParsedMatches() ([]*Match, error)
-	ListValveMatchesMissingDownloadUrl() ([]*Match, error)
-
-	UpdateResult(*Match) error
-	UpdateDownloadInformation(*Match) error
-	UpdateStatus(*Match) error
-	UpdateStatusAndFilename(*Match) error
-
-	Delete(entity.ID) error
+	Create(context.Context, *Match) error
+
+	Find(context.Context, entity.ID) (*Match, error)
+	FindByFilename(ctx context.Context, filename string) (*Match, error)
+	FindByFaceitId(context.Context, string) (*Match, error)
+	FindByValveId(context.Context, uint64) (*Match, error)
+	FindByValveOutcomeId(context.Context, uint64) (*Match, error)
+	FindDuplicates(context.Context, *Match) ([]*Match, error)
+
+	List(context.Context) ([]*Match, error)
+	ListDownloadedMatches(context.Context) ([]*Match, error)
+	ListDownloadableMatches(ctx context.Context, expiredBefore time.Time) ([]*Match, error)
+	ListParsedMatches(context.Context) ([]*Match, error)
+	ListParsedMatchesByMap(ctx context.Context, mapName string) ([]*Match, error)
+	ListValveMatchesMissingDownloadUrl(ctx context.Context, expiredBefore time.Time) ([]*Match, error)
+	ListExpiredMatches(ctx context.Context, expiredBefore time.Time) ([]*Match, error)
+	CountByStatus(context.Context, Status) (int64, error)
+
+	UpdateResult(context.Context, *Match) error
+	UpdateDownloadInformation(context.Context, *Match) error
+	UpdateStatus(context.Context, *Match) error
+	UpdateStatusAndFilename(context.Context, *Match) error
+	UpdateFileState(context.Context, *Match) error
+	UpdateDownload(context.Context, *Match) error
+	UpdateDownloadAttempts(context.Context, *Match) error
+	UpdateFingerprint(context.Context, *Match) error
+	Replace(context.Context, *Match) error
+
+	Delete(context.Context, entity.ID) error
 }
 
 type UseCase interface {
-	CreateMatchFromManualUpload(filename string, matchTime time.Time) (*Match, error)
-	CreateMatchFromSharecode(*share_code.ShareCodeData) (*Match, error)
-	CreateDownloadableMatchFromFaceitId(string, string, time.Time) (*Match, error)
-
-	GetAll() ([]*Match, error)
-	GetAllParsed() ([]*Match, error)
-	GetMatch(entity.ID) (*Match, error)
-	GetMatchByFilename(filename string) (*Match, error)
-	GetMatchByValveId(uint64) (*Match, error)
-	GetMatchByValveOutcomeId(uint64) (*Match, error)
-	GetMatchByFaceitId(string) (*Match, error)
-	GetDownloadableMatches() ([]*Match, error)
-	GetValveMatchesMissingDownloadUrl() ([]*Match, error)
-	GetParseableMatches(parserVersion byte) ([]*Match, error)
-
-	UpdateStatus(*Match, Status) error
-	UpdateResult(m *Match, r *MatchResult, parserVersion byte) error
-	UpdateDownloadInformationForOutcomeId(matchId uint64, matchTime time.Time, url string) error
-	SetStatusAndFilename(m *Match, status Status, filename string) error
+	CreateMatchFromManualUpload(ctx context.Context, filename string, matchTime time.Time, fingerprint *demo.Fingerprint) (*Match, error)
+	CreateMatchFromSharecode(context.Context, *share_code.ShareCodeData) (*Match, error)
+	CreateDownloadableMatchFromFaceitId(context.Context, string, string, time.Time) (*Match, error)
+
+	GetAll(context.Context) ([]*Match, error)
+	GetAllParsed(context.Context) ([]*Match, error)
+	GetParsedMatchesByMap(ctx context.Context, mapName string) ([]*Match, error)
+	GetMatch(context.Context, entity.ID) (*Match, error)
+	GetMatchByFilename(ctx context.Context, filename string) (*Match, error)
+	GetMatchByValveId(context.Context, uint64) (*Match, error)
+	GetMatchByValveOutcomeId(context.Context, uint64) (*Match, error)
+	GetMatchByFaceitId(context.Context, string) (*Match, error)
+	GetDownloadableMatches(context.Context) ([]*Match, error)
+	GetValveMatchesMissingDownloadUrl(context.Context) ([]*Match, error)
+	GetParseableMatches(ctx context.Context, parserVersion byte) ([]*Match, error)
+	ExpireMatches(context.Context) (expired int, total int64, err error)
+	ExpireMatch(context.Context, *Match) (bool, error)
+
+	UpdateStatus(ctx context.Context, m *Match, status Status, reason string) error
+	UpdateResult(ctx context.Context, m *Match, r *MatchResult, parserVersion byte) error
+	UpdateDownloadInformationForOutcomeId(ctx context.Context, matchId uint64, matchTime time.Time, url string) (*Match, error)
+	SetStatusAndFilename(ctx context.Context, m *Match, status Status, filename string) error
+	SetFileState(ctx context.Context, m *Match, state FileState, filename string) error
+	CompleteDownload(ctx context.Context, m *Match, filename string, download *DownloadInfo, fingerprint *demo.Fingerprint) error
+	SetFingerprint(ctx context.Context, m *Match, fingerprint *demo.Fingerprint) error
+	MergeDuplicates(ctx context.Context, m *Match) (target *Match, merged []*Match, err error)
+	FailDownload(ctx context.Context, m *Match, attempts int, maxAttempts int, downloadErr error) error
 }
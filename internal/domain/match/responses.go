@@ -1,9 +1,14 @@
 package match
 
 import (
+	"sort"
 	"time"
 
+	"github.com/Cludch/csgo-tools/internal/demoparser"
 	"github.com/Cludch/csgo-tools/internal/domain/entity"
+	"github.com/Cludch/csgo-tools/pkg/util"
+	"github.com/markus-wa/demoinfocs-golang/v2/pkg/demoinfocs/common"
+	"github.com/markus-wa/demoinfocs-golang/v2/pkg/demoinfocs/events"
 )
 
 type MatchList struct {
@@ -18,3 +23,358 @@ type MatchListEntry struct {
 	TeamTwoScore byte      `json:"teamTwoScore"`
 	ClanWon      bool      `json:"clanWon"`
 }
+
+// MapSiteStats describes the bomb site statistics of all parsed matches on one map.
+type MapSiteStats struct {
+	Map    string       `json:"map"`
+	Rounds int          `json:"rounds"`
+	Plants int          `json:"plants"`
+	Sites  []*SiteStats `json:"sites"`
+}
+
+// SiteStats describes how rounds ended after the bomb has been planted on a site.
+type SiteStats struct {
+	Site string `json:"site"`
+	// Plants contains the amount of rounds in which the bomb has been planted on this site.
+	Plants int `json:"plants"`
+	// PlantRate describes the share of all plants that happened on this site.
+	PlantRate float32 `json:"plantRate"`
+	// PostPlantWins contains the amount of rounds the terrorists won after planting on this site.
+	PostPlantWins    int     `json:"postPlantWins"`
+	PostPlantWinRate float32 `json:"postPlantWinRate"`
+	// Retakes contains the amount of rounds the counter-terrorists won after the bomb has been planted on this site.
+	Retakes    int     `json:"retakes"`
+	RetakeRate float32 `json:"retakeRate"`
+	Defuses    int     `json:"defuses"`
+	Explosions int     `json:"explosions"`
+	KitDefuses int     `json:"kitDefuses"`
+}
+
+// NewMapSiteStats sums up the bomb plants of all rounds of the given matches per site.
+func NewMapSiteStats(mapName string, matches []*Match) *MapSiteStats {
+	stats := &MapSiteStats{Map: mapName, Sites: make([]*SiteStats, 0)}
+	sites := make(map[string]*SiteStats)
+
+	for _, m := range matches {
+		if m.Result == nil {
+			continue
+		}
+
+		for _, round := range m.Result.Rounds {
+			stats.Rounds++
+
+			bomb := round.Bomb
+			if bomb == nil || !bomb.IsPlanted {
+				continue
+			}
+
+			site, ok := sites[bomb.Site]
+			if !ok {
+				site = &SiteStats{Site: bomb.Site}
+				sites[bomb.Site] = site
+				stats.Sites = append(stats.Sites, site)
+			}
+
+			stats.Plants++
+			site.Plants++
+
+			switch round.WinnerSide {
+			case common.TeamTerrorists:
+				site.PostPlantWins++
+			case common.TeamCounterTerrorists:
+				site.Retakes++
+			}
+
+			if bomb.IsDefused {
+				site.Defuses++
+
+				if bomb.HasKit {
+					site.KitDefuses++
+				}
+			}
+
+			if bomb.IsExploded {
+				site.Explosions++
+			}
+		}
+	}
+
+	sort.Slice(stats.Sites, func(i, j int) bool { return stats.Sites[i].Site < stats.Sites[j].Site })
+
+	for _, site := range stats.Sites {
+		site.PlantRate = float32(site.Plants) / float32(stats.Plants)
+		site.PostPlantWinRate = float32(site.PostPlantWins) / float32(site.Plants)
+		site.RetakeRate = float32(site.Retakes) / float32(site.Plants)
+	}
+
+	return stats
+}
+
+// MatchDamage describes the damage all players dealt to each other during one match.
+type MatchDamage struct {
+	ID      entity.ID       `json:"id"`
+	Players []*PlayerDamage `json:"players"`
+	// Matrix contains the health damage each victim (row) took from each attacker (column) in the order of the players,
+	// i.e. Matrix[victim][attacker].
+	Matrix [][]int `json:"matrix"`
+}
+
+// PlayerDamage describes the damage dealt to enemies by one player.
+type PlayerDamage struct {
+	SteamID       uint64 `json:"id"`
+	Name          string `json:"name"`
+	Damage        int    `json:"damage"`
+	ArmorDamage   int    `json:"armorDamage"`
+	GunDamage     int    `json:"gunDamage"`
+	UtilityDamage int    `json:"utilityDamage"`
+	// HitGroups contains the damage dealt with guns per hit group.
+	HitGroups map[string]int `json:"hitGroups"`
+	// DamageBeforeDeath contains the damage dealt per round until the player died or the round ended.
+	DamageBeforeDeath []int `json:"damageBeforeDeath"`
+}
+
+var hitGroupNames = map[events.HitGroup]string{
+	events.HitGroupGeneric:  "generic",
+	events.HitGroupHead:     "head",
+	events.HitGroupChest:    "chest",
+	events.HitGroupStomach:  "stomach",
+	events.HitGroupLeftArm:  "leftArm",
+	events.HitGroupRightArm: "rightArm",
+	events.HitGroupLeftLeg:  "leftLeg",
+	events.HitGroupRightLeg: "rightLeg",
+	events.HitGroupGear:     "gear",
+}
+
+// NewMatchDamage creates the damage matrix and the damage breakdown of every player from the damage events of each round.
+func NewMatchDamage(m *Match) *MatchDamage {
+	damage := &MatchDamage{ID: m.ID, Players: make([]*PlayerDamage, 0)}
+	if m.Result == nil {
+		return damage
+	}
+
+	indices := make(map[uint64]int)
+	teams := make(map[uint64]common.Team)
+	for _, team := range m.Result.Teams {
+		for _, p := range team.Players {
+			indices[p.SteamID] = len(damage.Players)
+			teams[p.SteamID] = team.TeamID
+			damage.Players = append(damage.Players, &PlayerDamage{SteamID: p.SteamID, Name: p.Name, HitGroups: make(map[string]int),
+				DamageBeforeDeath: make([]int, len(m.Result.Rounds))})
+		}
+	}
+
+	damage.Matrix = make([][]int, len(damage.Players))
+	for i := range damage.Matrix {
+		damage.Matrix[i] = make([]int, len(damage.Players))
+	}
+
+	for roundIndex, round := range m.Result.Rounds {
+		deaths := make(map[uint64]time.Duration)
+		for _, kill := range round.Kills {
+			deaths[kill.VictimID] = kill.Tick
+		}
+
+		for _, damageResult := range round.Damage {
+			attackerIndex, attackerFound := indices[damageResult.AttackerID]
+			victimIndex, victimFound := indices[damageResult.VictimID]
+			if !attackerFound || !victimFound || attackerIndex == victimIndex {
+				continue
+			}
+
+			damage.Matrix[victimIndex][attackerIndex] += damageResult.HealthDamage
+
+			// Team damage is only part of the matrix.
+			if teams[damageResult.AttackerID] == teams[damageResult.VictimID] {
+				continue
+			}
+
+			attacker := damage.Players[attackerIndex]
+			attacker.Damage += damageResult.HealthDamage
+			attacker.ArmorDamage += damageResult.ArmorDamage
+
+			if demoparser.IsGun(damageResult.Weapon) {
+				attacker.GunDamage += damageResult.HealthDamage
+				attacker.HitGroups[hitGroupNames[damageResult.HitGroup]] += damageResult.HealthDamage
+			} else if damageResult.Weapon.Class() == common.EqClassGrenade {
+				attacker.UtilityDamage += damageResult.HealthDamage
+			}
+
+			if death, died := deaths[damageResult.AttackerID]; !died || damageResult.Tick <= death {
+				attacker.DamageBeforeDeath[roundIndex] += damageResult.HealthDamage
+			}
+		}
+	}
+
+	return damage
+}
+
+// MatchOpeningDuels describes the opening duels of all players during one match.
+type MatchOpeningDuels struct {
+	ID      entity.ID            `json:"id"`
+	Players []*PlayerOpeningDuel `json:"players"`
+	// Matrix contains the opening duels won by each player (row) against each other player (column) in the order of the players.
+	Matrix [][]int `json:"matrix"`
+}
+
+// PlayerOpeningDuel describes the opening duels and the entry success per side of one player.
+type PlayerOpeningDuel struct {
+	SteamID              uint64  `json:"id"`
+	Name                 string  `json:"name"`
+	Attempts             int     `json:"attempts"`
+	EntryKills           int     `json:"entryKills"`
+	EntrySuccessRate     float32 `json:"entrySuccessRate"`
+	AttemptsAsT          int     `json:"attemptsAsT"`
+	EntryKillsAsT        int     `json:"entryKillsAsT"`
+	EntrySuccessRateAsT  float32 `json:"entrySuccessRateAsT"`
+	AttemptsAsCT         int     `json:"attemptsAsCT"`
+	EntryKillsAsCT       int     `json:"entryKillsAsCT"`
+	EntrySuccessRateAsCT float32 `json:"entrySuccessRateAsCT"`
+}
+
+// NewMatchOpeningDuels creates the head-to-head matrix of the opening duels and the entry success of every player.
+func NewMatchOpeningDuels(m *Match) *MatchOpeningDuels {
+	duels := &MatchOpeningDuels{ID: m.ID, Players: make([]*PlayerOpeningDuel, 0)}
+	if m.Result == nil {
+		return duels
+	}
+
+	indices := make(map[uint64]int)
+	for _, team := range m.Result.Teams {
+		for _, p := range team.Players {
+			indices[p.SteamID] = len(duels.Players)
+			duels.Players = append(duels.Players, &PlayerOpeningDuel{SteamID: p.SteamID, Name: p.Name,
+				Attempts: int(p.OpeningDuelAttempts), EntryKills: int(p.EntryKills),
+				AttemptsAsT: int(p.OpeningDuelAttemptsAsT), EntryKillsAsT: int(p.EntryKillsAsT),
+				AttemptsAsCT: int(p.OpeningDuelAttemptsAsCT), EntryKillsAsCT: int(p.EntryKillsAsCT)})
+		}
+	}
+
+	duels.Matrix = make([][]int, len(duels.Players))
+	for i := range duels.Matrix {
+		duels.Matrix[i] = make([]int, len(duels.Players))
+	}
+
+	for _, round := range m.Result.Rounds {
+		duel := round.OpeningDuel
+		if duel == nil {
+			continue
+		}
+
+		killerIndex, killerFound := indices[duel.KillerID]
+		victimIndex, victimFound := indices[duel.VictimID]
+		if killerFound && victimFound {
+			duels.Matrix[killerIndex][victimIndex]++
+		}
+	}
+
+	for _, p := range duels.Players {
+		p.EntrySuccessRate = util.Rate(p.EntryKills, p.Attempts)
+		p.EntrySuccessRateAsT = util.Rate(p.EntryKillsAsT, p.AttemptsAsT)
+		p.EntrySuccessRateAsCT = util.Rate(p.EntryKillsAsCT, p.AttemptsAsCT)
+	}
+
+	return duels
+}
+
+// TimelineEventType describes the kind of an event on the round timeline.
+type TimelineEventType string
+
+const (
+	FreezetimeEnd TimelineEventType = "FreezetimeEnd"
+	FirstContact  TimelineEventType = "FirstContact"
+	Kill          TimelineEventType = "Kill"
+	BombPlant     TimelineEventType = "BombPlant"
+	BombDefuse    TimelineEventType = "BombDefuse"
+	UtilityThrown TimelineEventType = "UtilityThrown"
+	RoundEnd      TimelineEventType = "RoundEnd"
+)
+
+// RoundTimeline contains all events of one round ordered by the time they happened.
+type RoundTimeline struct {
+	ID          entity.ID        `json:"id"`
+	RoundNumber byte             `json:"roundNumber"`
+	Duration    float64          `json:"duration"`
+	Events      []*TimelineEvent `json:"events"`
+}
+
+// TimelineEvent describes a single event of a round.
+// Depending on the type, the player is the killer, planter, defuser or thrower and the target is the victim.
+type TimelineEvent struct {
+	// Time contains the seconds since the round started.
+	Time     float64           `json:"time"`
+	Type     TimelineEventType `json:"type"`
+	PlayerID uint64            `json:"playerId,omitempty"`
+	TargetID uint64            `json:"targetId,omitempty"`
+	Weapon   string            `json:"weapon,omitempty"`
+	Site     string            `json:"site,omitempty"`
+	Side     common.Team       `json:"side,omitempty"`
+	// Reason contains the reason why the round ended.
+	Reason events.RoundEndReason `json:"reason,omitempty"`
+}
+
+// NewRoundTimeline creates the ordered timeline of one round of the match.
+// The round number is one-based. Nil is returned if the round does not exist.
+func NewRoundTimeline(m *Match, roundNumber int) *RoundTimeline {
+	if m.Result == nil || roundNumber < 1 || roundNumber > len(m.Result.Rounds) {
+		return nil
+	}
+
+	round := m.Result.Rounds[roundNumber-1]
+	timeline := &RoundTimeline{ID: m.ID, RoundNumber: round.RoundNumber, Duration: round.Duration.Seconds(), Events: make([]*TimelineEvent, 0)}
+	secondsIntoRound := func(tick time.Duration) float64 {
+		return (tick - round.StartTick).Seconds()
+	}
+
+	if round.FreezetimeEndTick > 0 {
+		timeline.Events = append(timeline.Events, &TimelineEvent{Time: secondsIntoRound(round.FreezetimeEndTick), Type: FreezetimeEnd})
+	}
+
+	teams := make(map[uint64]common.Team)
+	for _, team := range m.Result.Teams {
+		for _, p := range team.Players {
+			teams[p.SteamID] = team.TeamID
+		}
+	}
+
+	// The first damage between two enemies is the first contact of the round.
+	for _, damage := range round.Damage {
+		attackerTeam, attackerFound := teams[damage.AttackerID]
+		victimTeam, victimFound := teams[damage.VictimID]
+		if !attackerFound || !victimFound || attackerTeam == victimTeam {
+			continue
+		}
+
+		timeline.Events = append(timeline.Events, &TimelineEvent{Time: secondsIntoRound(damage.Tick), Type: FirstContact,
+			PlayerID: damage.AttackerID, TargetID: damage.VictimID, Weapon: damage.Weapon.String()})
+		break
+	}
+
+	for _, kill := range round.Kills {
+		timeline.Events = append(timeline.Events, &TimelineEvent{Time: secondsIntoRound(kill.Tick), Type: Kill,
+			PlayerID: kill.KillerID, TargetID: kill.VictimID, Weapon: kill.Weapon.String()})
+	}
+
+	for _, utility := range round.Utility {
+		timeline.Events = append(timeline.Events, &TimelineEvent{Time: secondsIntoRound(utility.Tick), Type: UtilityThrown,
+			PlayerID: utility.PlayerID, Weapon: utility.Type.String()})
+	}
+
+	if bomb := round.Bomb; bomb != nil {
+		if bomb.IsPlanted {
+			timeline.Events = append(timeline.Events, &TimelineEvent{Time: secondsIntoRound(bomb.PlantTick), Type: BombPlant,
+				PlayerID: bomb.PlanterID, Site: bomb.Site})
+		}
+
+		if bomb.IsDefused {
+			timeline.Events = append(timeline.Events, &TimelineEvent{Time: secondsIntoRound(bomb.DefuseTick), Type: BombDefuse,
+				PlayerID: bomb.DefuserID, Site: bomb.Site})
+		}
+	}
+
+	timeline.Events = append(timeline.Events, &TimelineEvent{Time: round.Duration.Seconds(), Type: RoundEnd,
+		Side: round.WinnerSide, Reason: round.WinReason})
+
+	sort.SliceStable(timeline.Events, func(i, j int) bool { return timeline.Events[i].Time < timeline.Events[j].Time })
+
+	return timeline
+}
@@ -0,0 +1,124 @@
+package match
+
+import (
+	"sort"
+	"time"
+)
+
+// RetentionPolicy describes which demo files are kept. A demo is kept if any of the enabled rules applies to it.
+// Rules are disabled by their zero value. Demos that have never been parsed are always kept.
+type RetentionPolicy struct {
+	// MaxAge keeps all demos of matches younger than the given duration.
+	MaxAge time.Duration
+	// KeepPerUser keeps the latest demos of each user.
+	KeepPerUser int
+	UserIDs     []uint64
+	// KeepParserVersion keeps all demos that have been parsed with the given parser version.
+	KeepParserVersion byte
+	// MaxDiskUsage deletes the oldest parsed demos, even if kept by another rule, until the demos use less bytes.
+	MaxDiskUsage int64
+}
+
+func (p *RetentionPolicy) hasKeepRule() bool {
+	return p.MaxAge > 0 || p.KeepPerUser > 0 || p.KeepParserVersion > 0
+}
+
+// SelectExpiredDemos returns the matches whose demo files should be deleted according to the policy.
+// The sizes of the demo files are used to enforce the maximum disk usage.
+func SelectExpiredDemos(matches []*Match, sizes map[string]int64, policy *RetentionPolicy, now time.Time) []*Match {
+	stored := make([]*Match, 0, len(matches))
+	var usage int64
+	for _, m := range matches {
+		if m.Filename == "" || m.FileState == FileDeleted {
+			continue
+		}
+
+		stored = append(stored, m)
+		usage += sizes[m.Filename]
+	}
+
+	// Oldest matches first.
+	sort.SliceStable(stored, func(i, j int) bool { return stored[i].Time.Before(stored[j].Time) })
+
+	kept := policy.keptByUser(stored)
+	expired := make([]*Match, 0)
+	remaining := make([]*Match, 0, len(stored))
+
+	for _, m := range stored {
+		if m.Status != Parsed || m.Result == nil {
+			continue
+		}
+
+		if policy.hasKeepRule() && !policy.keeps(m, kept, now) {
+			expired = append(expired, m)
+			usage -= sizes[m.Filename]
+			continue
+		}
+
+		remaining = append(remaining, m)
+	}
+
+	if policy.MaxDiskUsage <= 0 {
+		return expired
+	}
+
+	for _, m := range remaining {
+		if usage <= policy.MaxDiskUsage {
+			break
+		}
+
+		expired = append(expired, m)
+		usage -= sizes[m.Filename]
+	}
+
+	return expired
+}
+
+func (p *RetentionPolicy) keeps(m *Match, keptByUser map[*Match]bool, now time.Time) bool {
+	if p.MaxAge > 0 && now.Sub(m.Time) < p.MaxAge {
+		return true
+	}
+
+	if p.KeepParserVersion > 0 && m.Result.ParserVersion == p.KeepParserVersion {
+		return true
+	}
+
+	return keptByUser[m]
+}
+
+// Returns the latest matches of each user. The matches have to be sorted by time ascending.
+func (p *RetentionPolicy) keptByUser(matches []*Match) map[*Match]bool {
+	kept := make(map[*Match]bool)
+	if p.KeepPerUser <= 0 {
+		return kept
+	}
+
+	for _, userID := range p.UserIDs {
+		count := 0
+		for i := len(matches) - 1; i >= 0 && count < p.KeepPerUser; i-- {
+			if matches[i].hasPlayer(userID) {
+				kept[matches[i]] = true
+				count++
+			}
+		}
+	}
+
+	return kept
+}
+
+// Returns whether the player played in the match.
+func (m *Match) hasPlayer(steamID uint64) bool {
+	if m.Result == nil {
+		return false
+	}
+
+	for _, team := range m.Result.Teams {
+		for _, p := range team.Players {
+			if p.SteamID == steamID {
+				return true
+			}
+		}
+	}
+
+	return false
+}
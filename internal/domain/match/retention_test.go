@@ -0,0 +1,60 @@
+package match_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Cludch/csgo-tools/internal/domain/match"
+	"github.com/Cludch/csgo-tools/internal/domain/player"
+	"github.com/stretchr/testify/assert"
+)
+
+var now = time.Date(2022, 1, 31, 0, 0, 0, 0, time.UTC)
+
+// Creates a parsed match, which has been played days ago by the player.
+func newParsedMatch(filename string, days int, steamID uint64, parserVersion byte) *match.Match {
+	return &match.Match{Filename: filename, Status: match.Parsed, FileState: match.FileArchived, Time: now.AddDate(0, 0, -days),
+		Result: &match.MatchResult{ParserVersion: parserVersion, Teams: []*match.TeamResult{
+			{Players: []*player.PlayerResult{{SteamID: steamID}}},
+		}}}
+}
+
+func filenames(matches []*match.Match) []string {
+	names := make([]string, len(matches))
+	for i, m := range matches {
+		names[i] = m.Filename
+	}
+	return names
+}
+
+func TestSelectExpiredDemosMaxAge(t *testing.T) {
+	unparsed := &match.Match{Filename: "unparsed.dem", Status: match.Downloaded, Time: now.AddDate(0, 0, -60)}
+	matches := []*match.Match{newParsedMatch("old.dem.zst", 40, 1, 1), newParsedMatch("new.dem.zst", 5, 1, 1), unparsed}
+
+	expired := match.SelectExpiredDemos(matches, nil, &match.RetentionPolicy{MaxAge: 30 * 24 * time.Hour}, now)
+
+	assert.Equal(t, []string{"old.dem.zst"}, filenames(expired))
+}
+
+func TestSelectExpiredDemosKeepPerUser(t *testing.T) {
+	matches := []*match.Match{
+		newParsedMatch("a.dem", 40, 1, 1),
+		newParsedMatch("b.dem", 30, 1, 1),
+		newParsedMatch("c.dem", 20, 1, 1),
+		newParsedMatch("d.dem", 50, 2, 2),
+	}
+
+	policy := &match.RetentionPolicy{KeepPerUser: 2, UserIDs: []uint64{1}, KeepParserVersion: 2}
+	expired := match.SelectExpiredDemos(matches, nil, policy, now)
+
+	assert.Equal(t, []string{"a.dem"}, filenames(expired))
+}
+
+func TestSelectExpiredDemosMaxDiskUsage(t *testing.T) {
+	matches := []*match.Match{newParsedMatch("new.dem", 1, 1, 1), newParsedMatch("old.dem", 10, 1, 1), newParsedMatch("older.dem", 20, 1, 1)}
+	sizes := map[string]int64{"new.dem": 100, "old.dem": 100, "older.dem": 100}
+
+	expired := match.SelectExpiredDemos(matches, sizes, &match.RetentionPolicy{MaxDiskUsage: 150}, now)
+
+	assert.Equal(t, []string{"older.dem", "old.dem"}, filenames(expired))
+}
@@ -1,103 +1,214 @@
 package match
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"time"
 
+	"github.com/Cludch/csgo-tools/internal/config"
 	"github.com/Cludch/csgo-tools/internal/domain/entity"
+	"github.com/Cludch/csgo-tools/pkg/demo"
 	"github.com/Cludch/csgo-tools/pkg/share_code"
+	log "github.com/sirupsen/logrus"
 )
 
 type Service struct {
-	repo Repository
+	repo                 Repository
+	configurationService config.UseCase
 }
 
-func NewService(r Repository) *Service {
+func NewService(r Repository, c config.UseCase) *Service {
 	return &Service{
-		repo: r,
+		repo:                 r,
+		configurationService: c,
 	}
 }
 
-func (s *Service) UpdateDownloadInformationForOutcomeId(id uint64, matchTime time.Time, url string) error {
-	m, err := s.GetMatchByValveOutcomeId(id)
+// UpdateDownloadInformationForOutcomeId stores the download url of a matchmaking match and returns the match.
+func (s *Service) UpdateDownloadInformationForOutcomeId(ctx context.Context, id uint64, matchTime time.Time, url string) (*Match, error) {
+	m, err := s.GetMatchByValveOutcomeId(ctx, id)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if m.DownloadURL != "" || m.Status != Created {
-		return errors.New("download information already exists")
+		return nil, errors.New("download information already exists")
+	}
+
+	if err := m.SetStatus(Downloadable, "received the download url from the GameCoordinator"); err != nil {
+		return nil, err
 	}
 
-	m.Status = Downloadable
 	m.Time = matchTime
 	m.DownloadURL = url
 
 	if err := m.Validate(); err != nil {
-		return err
+		return nil, err
 	}
 
-	return s.repo.UpdateDownloadInformation(m)
+	return m, s.repo.UpdateDownloadInformation(ctx, m)
 }
 
-func (s *Service) GetMatch(id entity.ID) (*Match, error) {
-	return s.repo.Find(id)
+func (s *Service) GetMatch(ctx context.Context, id entity.ID) (*Match, error) {
+	return s.repo.Find(ctx, id)
 }
 
-func (s *Service) GetMatchByFilename(filename string) (*Match, error) {
-	return s.repo.FindByFilename(filename)
+func (s *Service) GetMatchByFilename(ctx context.Context, filename string) (*Match, error) {
+	return s.repo.FindByFilename(ctx, filename)
 }
 
-func (s *Service) GetAll() ([]*Match, error) {
-	return s.repo.List()
+func (s *Service) GetAll(ctx context.Context) ([]*Match, error) {
+	return s.repo.List(ctx)
 }
 
-func (s *Service) GetAllParsed() ([]*Match, error) {
-	return s.repo.ListParsedMatches()
+func (s *Service) GetAllParsed(ctx context.Context) ([]*Match, error) {
+	return s.repo.ListParsedMatches(ctx)
 }
 
-func (s *Service) GetMatchByValveId(id uint64) (*Match, error) {
-	return s.repo.FindByValveId(id)
+func (s *Service) GetParsedMatchesByMap(ctx context.Context, mapName string) ([]*Match, error) {
+	return s.repo.ListParsedMatchesByMap(ctx, mapName)
 }
 
-func (s *Service) GetMatchByValveOutcomeId(id uint64) (*Match, error) {
-	return s.repo.FindByValveOutcomeId(id)
+func (s *Service) GetMatchByValveId(ctx context.Context, id uint64) (*Match, error) {
+	return s.repo.FindByValveId(ctx, id)
 }
 
-func (s *Service) GetMatchByFaceitId(id string) (*Match, error) {
-	return s.repo.FindByFaceitId(id)
+func (s *Service) GetMatchByValveOutcomeId(ctx context.Context, id uint64) (*Match, error) {
+	return s.repo.FindByValveOutcomeId(ctx, id)
 }
 
-func (s *Service) GetDownloadableMatches() ([]*Match, error) {
-	return s.repo.ListDownloadableMatches()
+func (s *Service) GetMatchByFaceitId(ctx context.Context, id string) (*Match, error) {
+	return s.repo.FindByFaceitId(ctx, id)
 }
 
-func (s *Service) GetValveMatchesMissingDownloadUrl() ([]*Match, error) {
-	return s.repo.ListValveMatchesMissingDownloadUrl()
+// GetDownloadableMatches returns the matches to download. Expired matchmaking matches are skipped.
+func (s *Service) GetDownloadableMatches(ctx context.Context) ([]*Match, error) {
+	return s.repo.ListDownloadableMatches(ctx, s.expiredBefore())
 }
 
-func (s *Service) SetStatusAndFilename(m *Match, st Status, f string) error {
-	m.Status = st
+// GetValveMatchesMissingDownloadUrl returns the matchmaking matches missing their download url. Expired matches are
+// skipped.
+func (s *Service) GetValveMatchesMissingDownloadUrl(ctx context.Context) ([]*Match, error) {
+	return s.repo.ListValveMatchesMissingDownloadUrl(ctx, s.expiredBefore())
+}
+
+// ExpireMatches marks all matchmaking matches as expired whose demo has not been downloaded before Valve deleted it.
+// Returns the amount of matches that expired now and in total.
+func (s *Service) ExpireMatches(ctx context.Context) (int, int64, error) {
+	matches, err := s.repo.ListExpiredMatches(ctx, s.expiredBefore())
+	if err != nil && !errors.Is(err, entity.ErrNotFound) {
+		return 0, 0, err
+	}
+
+	expired := 0
+	for _, m := range matches {
+		ok, err := s.ExpireMatch(ctx, m)
+		if err != nil {
+			return expired, 0, err
+		}
+
+		if ok {
+			expired++
+		}
+	}
+
+	total, err := s.repo.CountByStatus(ctx, Expired)
+	return expired, total, err
+}
+
+// ExpireMatch marks the match as expired if its demo has been deleted by Valve. Returns whether the match expired.
+func (s *Service) ExpireMatch(ctx context.Context, m *Match) (bool, error) {
+	if !m.IsExpired(s.expiredBefore()) {
+		return false, nil
+	}
+
+	const reason = "the demo is older than %d days and has been deleted by Valve"
+	return true, s.UpdateStatus(ctx, m, Expired, fmt.Sprintf(reason, s.configurationService.GetDownloadConfig().ExpiryDays))
+}
+
+// Returns the time before which matchmaking demos are expired.
+func (s *Service) expiredBefore() time.Time {
+	return ExpiredBefore(time.Now(), s.configurationService.GetDownloadConfig().ExpiryDays)
+}
+
+func (s *Service) SetStatusAndFilename(ctx context.Context, m *Match, st Status, f string) error {
+	if err := m.SetStatus(st, ""); err != nil {
+		return err
+	}
+
 	m.Filename = f
 
 	if err := m.Validate(); err != nil {
 		return err
 	}
 
-	return s.repo.UpdateStatusAndFilename(m)
+	return s.repo.UpdateStatusAndFilename(ctx, m)
 }
 
-func (s *Service) UpdateStatus(m *Match, st Status) error {
-	m.Status = st
+// SetFileState sets the state of the demo file and its filename, which changes when the demo gets archived.
+func (s *Service) SetFileState(ctx context.Context, m *Match, state FileState, filename string) error {
+	m.FileState = state
+	m.Filename = filename
+
+	return s.repo.UpdateFileState(ctx, m)
+}
+
+// CompleteDownload marks the match as downloaded and stores the filename, the information about the download and the
+// fingerprint of the demo.
+func (s *Service) CompleteDownload(ctx context.Context, m *Match, filename string, download *DownloadInfo, fingerprint *demo.Fingerprint) error {
+	if err := m.SetStatus(Downloaded, "downloaded the demo"); err != nil {
+		return err
+	}
+
+	m.Filename = filename
+	m.FileState = FileStateOf(filename)
+	m.Download = download
+	m.Fingerprint = fingerprint
+	m.LastError = ""
+
+	if err := m.Validate(); err != nil {
+		return err
+	}
+
+	return s.repo.UpdateDownload(ctx, m)
+}
+
+// FailDownload records a failed attempt to download the demo as mirrored from the download job and sets the status
+// to Error once no attempts are left.
+func (s *Service) FailDownload(ctx context.Context, m *Match, attempts int, maxAttempts int, downloadErr error) error {
+	if err := m.FailDownload(attempts, maxAttempts, downloadErr); err != nil {
+		return err
+	}
+
+	if err := m.Validate(); err != nil {
+		return err
+	}
+
+	return s.repo.UpdateDownloadAttempts(ctx, m)
+}
+
+// UpdateStatus changes the status of the match. The reason, e.g. an error message, is recorded in the status history
+// and kept as last error of matches changing to Error.
+// Returns a *TransitionError if the match can not change to the status.
+func (s *Service) UpdateStatus(ctx context.Context, m *Match, st Status, reason string) error {
+	if err := m.SetStatus(st, reason); err != nil {
+		return err
+	}
+
+	if st == Error {
+		m.LastError = reason
+	}
 
 	if err := m.Validate(); err != nil {
 		return err
 	}
 
-	return s.repo.UpdateStatus(m)
+	return s.repo.UpdateStatus(ctx, m)
 }
 
-func (s *Service) CreateDownloadableMatchFromFaceitId(faceitMatchId string, downloadUrl string, startTime time.Time) (*Match, error) {
-	dbMatch, err := s.GetMatchByFaceitId(faceitMatchId)
+func (s *Service) CreateDownloadableMatchFromFaceitId(ctx context.Context, faceitMatchId string, downloadUrl string, startTime time.Time) (*Match, error) {
+	dbMatch, err := s.GetMatchByFaceitId(ctx, faceitMatchId)
 	if err != nil && !errors.Is(err, entity.ErrNotFound) || dbMatch != nil {
 		return dbMatch, err
 	}
@@ -105,51 +216,158 @@ func (s *Service) CreateDownloadableMatchFromFaceitId(faceitMatchId string, down
 	m, _ := NewMatch(Faceit)
 	m.FaceitMatchId = faceitMatchId
 	m.DownloadURL = downloadUrl
-	m.Status = Downloadable
+	if err := m.SetStatus(Downloadable, "received the download url from Faceit"); err != nil {
+		return nil, err
+	}
+
 	m.Time = startTime
-	return m, s.repo.Create(m)
+	return m, s.repo.Create(ctx, m)
 }
 
-func (s *Service) CreateMatchFromSharecode(sc *share_code.ShareCodeData) (*Match, error) {
-	dbMatch, err := s.GetMatchByValveId(sc.MatchID)
+func (s *Service) CreateMatchFromSharecode(ctx context.Context, sc *share_code.ShareCodeData) (*Match, error) {
+	dbMatch, err := s.GetMatchByValveId(ctx, sc.MatchID)
 	if err != nil && !errors.Is(err, entity.ErrNotFound) || dbMatch != nil {
 		return dbMatch, err
 	}
 
 	m, _ := NewMatch(MatchMaking)
 	m.ShareCode = sc
-	return m, s.repo.Create(m)
+	return m, s.repo.Create(ctx, m)
 }
 
-func (s *Service) CreateMatchFromManualUpload(filename string, matchTime time.Time) (*Match, error) {
-	dbMatch, err := s.GetMatchByFilename(filename)
+// CreateMatchFromManualUpload creates a match for a demo file. If the fingerprint belongs to the demo of another match,
+// the file is added to that match, which is returned together with ErrDuplicateDemo.
+func (s *Service) CreateMatchFromManualUpload(ctx context.Context, filename string, matchTime time.Time, fingerprint *demo.Fingerprint) (*Match, error) {
+	dbMatch, err := s.GetMatchByFilename(ctx, filename)
 	if err != nil && !errors.Is(err, entity.ErrNotFound) || dbMatch != nil {
 		return nil, nil
 	}
 
 	m, _ := NewMatch(Manual)
 	m.Filename = filename
+	m.FileState = FileStateOf(filename)
+	m.Fingerprint = fingerprint
 	m.Time = matchTime
-	m.Status = Downloaded
-	return m, s.repo.Create(m)
+	if err := m.SetStatus(Downloaded, "found the demo file"); err != nil {
+		return nil, err
+	}
+
+	if fingerprint != nil {
+		duplicates, err := s.getDuplicates(ctx, m)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(duplicates) > 0 {
+			original := duplicates[0]
+			if err := original.Merge(m); err != nil {
+				return nil, err
+			}
+
+			if err := s.repo.Replace(ctx, original); err != nil {
+				return nil, err
+			}
+
+			return original, ErrDuplicateDemo
+		}
+	}
+
+	return m, s.repo.Create(ctx, m)
+}
+
+// SetFingerprint stores the fingerprint of the demo.
+func (s *Service) SetFingerprint(ctx context.Context, m *Match, fingerprint *demo.Fingerprint) error {
+	m.Fingerprint = fingerprint
+
+	return s.repo.UpdateFingerprint(ctx, m)
 }
 
-func (s *Service) GetParseableMatches(parserVersion byte) ([]*Match, error) {
-	downloaded, errD := s.repo.ListDownloadedMatches()
+// MergeDuplicates merges all matches with the same demo into the oldest one, which is returned as target.
+// The merged matches are deleted and returned, thus results belonging to them can be deleted by the caller.
+func (s *Service) MergeDuplicates(ctx context.Context, m *Match) (*Match, []*Match, error) {
+	duplicates, err := s.getDuplicates(ctx, m)
+	if err != nil || len(duplicates) == 0 {
+		return m, nil, err
+	}
+
+	target := m
+	for _, duplicate := range duplicates {
+		if duplicate.CreatedAt.Before(target.CreatedAt) {
+			target = duplicate
+		}
+	}
+
+	merged := make([]*Match, 0, len(duplicates))
+	for _, duplicate := range append(duplicates, m) {
+		if duplicate == target {
+			continue
+		}
+
+		if err := target.Merge(duplicate); err != nil {
+			return m, nil, err
+		}
+
+		merged = append(merged, duplicate)
+	}
+
+	if err := s.repo.Replace(ctx, target); err != nil {
+		return m, nil, err
+	}
+
+	for _, duplicate := range merged {
+		if err := s.repo.Delete(ctx, duplicate.ID); err != nil {
+			return target, merged, err
+		}
+
+		const msg = "match: merged duplicate match %s into %s"
+		log.Infof(msg, duplicate.ID, target.ID)
+	}
+
+	return target, merged, nil
+}
+
+// Returns the other matches with the same demo.
+func (s *Service) getDuplicates(ctx context.Context, m *Match) ([]*Match, error) {
+	if m.Fingerprint == nil {
+		return nil, nil
+	}
+
+	candidates, err := s.repo.FindDuplicates(ctx, m)
+	if err != nil && !errors.Is(err, entity.ErrNotFound) {
+		return nil, err
+	}
+
+	duplicates := make([]*Match, 0, len(candidates))
+	for _, candidate := range candidates {
+		if m.Fingerprint.IsDuplicateOf(candidate.Fingerprint) {
+			duplicates = append(duplicates, candidate)
+		}
+	}
+
+	return duplicates, nil
+}
+
+func (s *Service) GetParseableMatches(ctx context.Context, parserVersion byte) ([]*Match, error) {
+	downloaded, errD := s.repo.ListDownloadedMatches(ctx)
 	if errD != nil {
 		return nil, errD
 	}
 
-	parsed, errP := s.repo.ListParsedMatches()
+	parsed, errP := s.repo.ListParsedMatches(ctx)
 	if errP != nil {
 		return nil, errP
 	}
 
-	parseable := make([]*Match, len(downloaded))
-	copy(parseable, downloaded)
+	parseable := make([]*Match, 0, len(downloaded))
+	for _, match := range downloaded {
+		if match.FileState != FileDeleted {
+			parseable = append(parseable, match)
+		}
+	}
 
+	// Demos deleted by the retention rules can no longer be reparsed.
 	for _, match := range parsed {
-		if match.Result.ParserVersion < parserVersion {
+		if match.Result.ParserVersion < parserVersion && match.FileState != FileDeleted {
 			parseable = append(parseable, match)
 		}
 	}
@@ -157,16 +375,17 @@ func (s *Service) GetParseableMatches(parserVersion byte) ([]*Match, error) {
 	return parseable, nil
 }
 
-func (s *Service) UpdateResult(m *Match, r *MatchResult, parserVersion byte) error {
+func (s *Service) UpdateResult(ctx context.Context, m *Match, r *MatchResult, parserVersion byte) error {
 	m.Result = r
 	m.Result.ParserVersion = parserVersion
-	err := s.repo.UpdateResult(m)
+	err := s.repo.UpdateResult(ctx, m)
 	if err != nil {
 		return err
 	}
 
 	if m.Status != Parsed {
-		return s.UpdateStatus(m, Parsed)
+		const reason = "parsed with parser version %d"
+		return s.UpdateStatus(ctx, m, Parsed, fmt.Sprintf(reason, parserVersion))
 	}
 
 	return nil
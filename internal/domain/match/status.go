@@ -0,0 +1,104 @@
+package match
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// transitions holds the statuses a match may change to from each status. Matches without a demo may get one by
+// merging a duplicate, thus they may change to Downloaded.
+var transitions = map[Status][]Status{
+	Created:      {Downloadable, Downloaded, Unavailable, Expired, Error},
+	Downloadable: {Downloaded, Unavailable, Expired, Error},
+	Downloaded:   {Parsed, Unavailable, Error},
+	Parsed:       {},
+	Unavailable:  {Downloaded},
+	Expired:      {Downloaded},
+	Error:        {Downloadable, Downloaded},
+}
+
+type actorKey struct{}
+
+// WithActor returns a context recording the component, e.g. the downloader, as actor of the status changes saved
+// using it.
+func WithActor(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, actorKey{}, name)
+}
+
+// ActorFrom returns the actor of the context. Defaults to the name of the binary.
+func ActorFrom(ctx context.Context) string {
+	if name, ok := ctx.Value(actorKey{}).(string); ok && name != "" {
+		return name
+	}
+
+	return filepath.Base(os.Args[0])
+}
+
+// StatusChange records one change of the status of a match.
+type StatusChange struct {
+	From Status    `json:"from" bson:"from,omitempty"`
+	To   Status    `json:"to" bson:"to"`
+	Time time.Time `json:"time" bson:"time"`
+	// Actor is the component that changed the status. It is set once the change gets saved.
+	Actor string `json:"actor" bson:"actor"`
+	// Reason describes why the status changed, e.g. the error message.
+	Reason string `json:"reason" bson:"reason,omitempty"`
+}
+
+// TransitionError is returned if the status of a match can not change to the requested status.
+type TransitionError struct {
+	From Status
+	To   Status
+}
+
+func (e *TransitionError) Error() string {
+	const msg = "match: illegal status transition from %s to %s"
+	return fmt.Sprintf(msg, e.From, e.To)
+}
+
+// CanTransition returns whether a match may change from one status to the other.
+func CanTransition(from Status, to Status) bool {
+	for _, allowed := range transitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SetStatus changes the status of the match and records the change in the status history.
+// Returns a *TransitionError if the change is not allowed. Setting the current status again is no change.
+func (m *Match) SetStatus(to Status, reason string) error {
+	if m.Status == to {
+		return nil
+	}
+
+	if m.Status != "" && !CanTransition(m.Status, to) {
+		return &TransitionError{From: m.Status, To: to}
+	}
+
+	change := &StatusChange{
+		From:   m.Status,
+		To:     to,
+		Time:   time.Now(),
+		Reason: reason,
+	}
+	m.StatusHistory = append(m.StatusHistory, change)
+	m.statusChanges = append(m.statusChanges, change)
+	m.Status = to
+
+	return nil
+}
+
+// Returns the status of the match as it has been loaded or saved last, i.e. before its unsaved status changes.
+func (m *Match) savedStatus() Status {
+	if len(m.statusChanges) > 0 {
+		return m.statusChanges[0].From
+	}
+
+	return m.Status
+}
@@ -0,0 +1,63 @@
+package match_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Cludch/csgo-tools/internal/domain/match"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetStatus(t *testing.T) {
+	m, _ := match.NewMatch(match.Faceit)
+
+	assert.Nil(t, m.SetStatus(match.Downloadable, ""))
+	assert.Nil(t, m.SetStatus(match.Downloaded, "demo downloaded"))
+	assert.Nil(t, m.SetStatus(match.Downloaded, "demo downloaded"))
+	assert.Nil(t, m.SetStatus(match.Parsed, ""))
+
+	assert.Equal(t, match.Parsed, m.Status)
+	assert.Len(t, m.StatusHistory, 4)
+
+	created := m.StatusHistory[0]
+	assert.Equal(t, match.Status(""), created.From)
+	assert.Equal(t, match.Created, created.To)
+
+	downloaded := m.StatusHistory[2]
+	assert.Equal(t, match.Downloadable, downloaded.From)
+	assert.Equal(t, match.Downloaded, downloaded.To)
+	assert.Equal(t, "demo downloaded", downloaded.Reason)
+	assert.False(t, downloaded.Time.IsZero())
+}
+
+func TestActorFrom(t *testing.T) {
+	ctx := context.Background()
+
+	assert.NotEmpty(t, match.ActorFrom(ctx))
+	assert.Equal(t, "download", match.ActorFrom(match.WithActor(ctx, "download")))
+}
+
+func TestSetStatusIllegal(t *testing.T) {
+	m, _ := match.NewMatch(match.Manual)
+	_ = m.SetStatus(match.Downloaded, "")
+	_ = m.SetStatus(match.Parsed, "")
+
+	err := m.SetStatus(match.Unavailable, "demo file deleted")
+
+	var transitionErr *match.TransitionError
+	assert.True(t, errors.As(err, &transitionErr))
+	assert.Equal(t, match.Parsed, transitionErr.From)
+	assert.Equal(t, match.Unavailable, transitionErr.To)
+	assert.Equal(t, match.Parsed, m.Status)
+	assert.Len(t, m.StatusHistory, 3)
+}
+
+func TestCanTransition(t *testing.T) {
+	assert.True(t, match.CanTransition(match.Created, match.Downloadable))
+	assert.True(t, match.CanTransition(match.Downloadable, match.Expired))
+	assert.True(t, match.CanTransition(match.Expired, match.Downloaded))
+	assert.False(t, match.CanTransition(match.Parsed, match.Downloaded))
+	assert.False(t, match.CanTransition(match.Downloaded, match.Created))
+	assert.False(t, match.CanTransition(match.Unavailable, match.Downloadable))
+}
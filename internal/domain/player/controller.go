@@ -3,7 +3,9 @@ package player
 import (
 	"net/http"
 	"strconv"
+	"time"
 
+	"github.com/Cludch/csgo-tools/pkg/util"
 	"github.com/gin-gonic/gin"
 )
 
@@ -18,7 +20,7 @@ func NewController(s UseCase) *Controller {
 }
 
 func (c *Controller) GetPlayers(g *gin.Context) {
-	players, _ := c.service.GetAll()
+	players, _ := c.service.GetAll(g.Request.Context())
 	playerList := &PlayerList{Players: make([]*PlayerListEntry, len(players))}
 
 	for i, player := range players {
@@ -40,19 +42,27 @@ func (c *Controller) GetPlayers(g *gin.Context) {
 
 func (c *Controller) GetPlayerDetails(g *gin.Context) {
 	id, _ := strconv.ParseUint(g.Param("id"), 10, 64)
-	player, _ := c.service.GetPlayer(id)
+	player, _ := c.service.GetPlayer(g.Request.Context(), id)
 	g.JSON(http.StatusOK, player)
 }
 
 func (c *Controller) GetPlayerAverageStats(g *gin.Context) {
 	id, _ := strconv.ParseUint(g.Param("id"), 10, 64)
-	player, _ := c.service.GetPlayer(id)
+	player, _ := c.service.GetPlayer(g.Request.Context(), id)
 
 	playerStats := &PlayerGameStats{}
 
 	// Keep track of the amount of rounds and all assists etc.
 	var matchRounds float32
 	assists, kills, entryKills, openingDuelAttempts, headshots, deaths, mvps, damageDealt := 0, 0, 0, 0, 0, 0, 0, 0
+	equipmentValue, moneySpent, kastRounds := 0, 0, 0
+	entryKillsAsT, openingDuelAttemptsAsT, entryKillsAsCT, openingDuelAttemptsAsCT := 0, 0, 0, 0
+	var multiKills [5]int
+	ecoRounds, ecoRoundsWon, halfBuyRounds, halfBuyRoundsWon := 0, 0, 0, 0
+	forceBuyRounds, forceBuyRoundsWon, fullBuyRounds, fullBuyRoundsWon := 0, 0, 0, 0
+	flashesThrown, smokesThrown, hesThrown, molotovsThrown := 0, 0, 0, 0
+	enemiesFlashed, teammatesFlashed, flashAssists, utilityDamage := 0, 0, 0, 0
+	var blindDuration time.Duration
 
 	for _, playerResult := range player.Results {
 		playerStats.Games++
@@ -65,17 +75,56 @@ func (c *Controller) GetPlayerAverageStats(g *gin.Context) {
 		kills += int(playerResult.Kills)
 		entryKills += int(playerResult.EntryKills)
 		openingDuelAttempts += int(playerResult.OpeningDuelAttempts)
+		entryKillsAsT += int(playerResult.EntryKillsAsT)
+		openingDuelAttemptsAsT += int(playerResult.OpeningDuelAttemptsAsT)
+		entryKillsAsCT += int(playerResult.EntryKillsAsCT)
+		openingDuelAttemptsAsCT += int(playerResult.OpeningDuelAttemptsAsCT)
 		headshots += int(playerResult.Headshots)
 		deaths += int(playerResult.Deaths)
 		mvps += int(playerResult.MVPs)
 		damageDealt += int(playerResult.DamageDealt)
-
+		kastRounds += int(playerResult.KASTRounds)
+		equipmentValue += playerResult.EquipmentValue
+		moneySpent += playerResult.MoneySpent
+
+		ecoRounds += int(playerResult.EcoRounds)
+		ecoRoundsWon += int(playerResult.EcoRoundsWon)
+		halfBuyRounds += int(playerResult.HalfBuyRounds)
+		halfBuyRoundsWon += int(playerResult.HalfBuyRoundsWon)
+		forceBuyRounds += int(playerResult.ForceBuyRounds)
+		forceBuyRoundsWon += int(playerResult.ForceBuyRoundsWon)
+		fullBuyRounds += int(playerResult.FullBuyRounds)
+		fullBuyRoundsWon += int(playerResult.FullBuyRoundsWon)
+
+		flashesThrown += int(playerResult.FlashesThrown)
+		smokesThrown += int(playerResult.SmokesThrown)
+		hesThrown += int(playerResult.HEsThrown)
+		molotovsThrown += int(playerResult.MolotovsThrown)
+		enemiesFlashed += int(playerResult.EnemiesFlashed)
+		teammatesFlashed += int(playerResult.TeammatesFlashed)
+		flashAssists += int(playerResult.FlashAssists)
+		utilityDamage += playerResult.HEDamage + playerResult.FireDamage
+		blindDuration += playerResult.BlindDuration
+
+		playerStats.Attempts1v1 += int(playerResult.Attempts1v1)
+		playerStats.Attempts1v2 += int(playerResult.Attempts1v2)
+		playerStats.Attempts1v3 += int(playerResult.Attempts1v3)
+		playerStats.Attempts1v4 += int(playerResult.Attempts1v4)
+		playerStats.Attempts1v5 += int(playerResult.Attempts1v5)
+		playerStats.Won1v1 += int(playerResult.Won1v1)
+		playerStats.Won1v2 += int(playerResult.Won1v2)
 		playerStats.Won1v3 += int(playerResult.Won1v3)
 		playerStats.Won1v4 += int(playerResult.Won1v4)
 		playerStats.Won1v5 += int(playerResult.Won1v5)
 		playerStats.RoundsWith3K += int(playerResult.RoundsWith3K)
 		playerStats.RoundsWith4K += int(playerResult.RoundsWith4K)
 		playerStats.RoundsWith5K += int(playerResult.RoundsWith5K)
+
+		multiKills[0] += int(playerResult.RoundsWith1K)
+		multiKills[1] += int(playerResult.RoundsWith2K)
+		multiKills[2] += int(playerResult.RoundsWith3K)
+		multiKills[3] += int(playerResult.RoundsWith4K)
+		multiKills[4] += int(playerResult.RoundsWith5K)
 	}
 
 	playerStats.AssistsPerRound += float32(assists) / matchRounds
@@ -86,6 +135,44 @@ func (c *Controller) GetPlayerAverageStats(g *gin.Context) {
 	playerStats.DeathsPerRound += float32(deaths) / matchRounds
 	playerStats.MVPsPerRound += float32(mvps) / matchRounds
 	playerStats.DamagePerRound += float32(damageDealt) / matchRounds
+	playerStats.KAST += float32(kastRounds) / matchRounds
+
+	// Ratings are calculated from the totals and thus weighted by the amount of rounds per match.
+	playerStats.Rating = RatingOne(kills, deaths, int(matchRounds), multiKills)
+	playerStats.RatingTwo = RatingTwo(playerStats.KAST, playerStats.KillsPerRound, playerStats.DeathsPerRound,
+		playerStats.AssistsPerRound, playerStats.DamagePerRound)
+	playerStats.EquipmentValuePerRound += float32(equipmentValue) / matchRounds
+	playerStats.MoneySpentPerRound += float32(moneySpent) / matchRounds
+	playerStats.FlashesThrownPerRound += float32(flashesThrown) / matchRounds
+	playerStats.SmokesThrownPerRound += float32(smokesThrown) / matchRounds
+	playerStats.HEsThrownPerRound += float32(hesThrown) / matchRounds
+	playerStats.MolotovsThrownPerRound += float32(molotovsThrown) / matchRounds
+	playerStats.EnemiesFlashedPerRound += float32(enemiesFlashed) / matchRounds
+	playerStats.TeammatesFlashedPerRound += float32(teammatesFlashed) / matchRounds
+	playerStats.BlindSecondsPerRound += float32(blindDuration.Seconds()) / matchRounds
+	playerStats.FlashAssistsPerRound += float32(flashAssists) / matchRounds
+	playerStats.UtilityDamagePerRound += float32(utilityDamage) / matchRounds
+
+	playerStats.EntrySuccessRate = util.Rate(entryKills, openingDuelAttempts)
+	playerStats.EntrySuccessRateAsT = util.Rate(entryKillsAsT, openingDuelAttemptsAsT)
+	playerStats.EntrySuccessRateAsCT = util.Rate(entryKillsAsCT, openingDuelAttemptsAsCT)
+
+	playerStats.EcoWinRate = util.Rate(ecoRoundsWon, ecoRounds)
+	playerStats.HalfBuyWinRate = util.Rate(halfBuyRoundsWon, halfBuyRounds)
+	playerStats.ForceBuyWinRate = util.Rate(forceBuyRoundsWon, forceBuyRounds)
+	playerStats.FullBuyWinRate = util.Rate(fullBuyRoundsWon, fullBuyRounds)
 
 	g.JSON(http.StatusOK, playerStats)
 }
+
+// GetPlayerWeaponStats sums up the weapon stats of one player across all matches.
+func (c *Controller) GetPlayerWeaponStats(g *gin.Context) {
+	id, _ := strconv.ParseUint(g.Param("id"), 10, 64)
+	player, err := c.service.GetPlayer(g.Request.Context(), id)
+	if err != nil {
+		g.JSON(http.StatusNotFound, gin.H{"error": "Player not found!"})
+		return
+	}
+
+	g.JSON(http.StatusOK, NewPlayerWeaponStats(player))
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/Cludch/csgo-tools/internal/domain/entity"
 	"github.com/go-playground/validator"
+	"github.com/markus-wa/demoinfocs-golang/v2/pkg/demoinfocs/common"
 )
 
 var validate = validator.New()
@@ -34,18 +35,73 @@ type PlayerResult struct {
 	Deaths              byte      `json:"deaths" bson:"deaths"`
 	DamageDealt         int       `json:"damageDealt" bson:"damageDealt"`
 	MVPs                byte      `json:"mvps" bson:"mvps"`
+	KASTRounds          byte      `json:"kastRounds" bson:"kastRounds"`
+	KAST                float32   `json:"kast" bson:"kast"`
+	ADR                 float32   `json:"adr" bson:"adr"`
+	Rating              float32   `json:"rating" bson:"rating"`
+	RatingTwo           float32   `json:"ratingTwo" bson:"ratingTwo"`
+	Attempts1v1         byte      `json:"attempts1v1" bson:"attempts1v1"`
+	Attempts1v2         byte      `json:"attempts1v2" bson:"attempts1v2"`
+	Attempts1v3         byte      `json:"attempts1v3" bson:"attempts1v3"`
+	Attempts1v4         byte      `json:"attempts1v4" bson:"attempts1v4"`
+	Attempts1v5         byte      `json:"attempts1v5" bson:"attempts1v5"`
+	Won1v1              byte      `json:"won1v1" bson:"won1v1"`
+	Won1v2              byte      `json:"won1v2" bson:"won1v2"`
 	Won1v3              byte      `json:"won1v3" bson:"won1v3"`
 	Won1v4              byte      `json:"won1v4" bson:"won1v4"`
 	Won1v5              byte      `json:"won1v5" bson:"won1v5"`
+	EquipmentValue      int       `json:"equipmentValue" bson:"equipmentValue"`
+	MoneySpent          int       `json:"moneySpent" bson:"moneySpent"`
+	EcoRounds           byte      `json:"ecoRounds" bson:"ecoRounds"`
+	EcoRoundsWon        byte      `json:"ecoRoundsWon" bson:"ecoRoundsWon"`
+	HalfBuyRounds       byte      `json:"halfBuyRounds" bson:"halfBuyRounds"`
+	HalfBuyRoundsWon    byte      `json:"halfBuyRoundsWon" bson:"halfBuyRoundsWon"`
+	ForceBuyRounds      byte      `json:"forceBuyRounds" bson:"forceBuyRounds"`
+	ForceBuyRoundsWon   byte      `json:"forceBuyRoundsWon" bson:"forceBuyRoundsWon"`
+	FullBuyRounds       byte      `json:"fullBuyRounds" bson:"fullBuyRounds"`
+	FullBuyRoundsWon    byte      `json:"fullBuyRoundsWon" bson:"fullBuyRoundsWon"`
+	RoundsWith1K        byte      `json:"roundsWith1k" bson:"1k"`
+	RoundsWith2K        byte      `json:"roundsWith2k" bson:"2k"`
 	RoundsWith3K        byte      `json:"roundsWith3k" bson:"3k"`
 	RoundsWith4K        byte      `json:"roundsWith4k" bson:"4k"`
 	RoundsWith5K        byte      `json:"roundsWith5k" bson:"5k"`
+	// Opening duels
+	EntryKillsAsT           byte `json:"entryKillsAsT" bson:"entryKillsAsT"`
+	OpeningDuelAttemptsAsT  byte `json:"openingDuelAttemptsAsT" bson:"openingDuelAttemptsAsT"`
+	EntryKillsAsCT          byte `json:"entryKillsAsCT" bson:"entryKillsAsCT"`
+	OpeningDuelAttemptsAsCT byte `json:"openingDuelAttemptsAsCT" bson:"openingDuelAttemptsAsCT"`
+	// Utility
+	FlashesThrown    byte          `json:"flashesThrown" bson:"flashesThrown"`
+	SmokesThrown     byte          `json:"smokesThrown" bson:"smokesThrown"`
+	HEsThrown        byte          `json:"hesThrown" bson:"hesThrown"`
+	MolotovsThrown   byte          `json:"molotovsThrown" bson:"molotovsThrown"`
+	DecoysThrown     byte          `json:"decoysThrown" bson:"decoysThrown"`
+	EnemiesFlashed   byte          `json:"enemiesFlashed" bson:"enemiesFlashed"`
+	TeammatesFlashed byte          `json:"teammatesFlashed" bson:"teammatesFlashed"`
+	BlindDuration    time.Duration `json:"blindDuration" bson:"blindDuration"`
+	FlashAssists     byte          `json:"flashAssists" bson:"flashAssists"`
+	HEDamage         int           `json:"heDamage" bson:"heDamage"`
+	FireDamage       int           `json:"fireDamage" bson:"fireDamage"`
+	// Weapons
+	Weapons []*WeaponResult `json:"weapons" bson:"weapons"`
 	// Rank
 	WinCount int `json:"wins" bson:"winCount"`
 	RankOld  int `json:"rankOld" bson:"rankOld"`
 	RankNew  int `json:"rankNew" bson:"rankNew"`
 }
 
+// WeaponResult holds the performance of a player with one weapon in one game.
+type WeaponResult struct {
+	Weapon    string `json:"weapon" bson:"weapon" validate:"required"`
+	Kills     int    `json:"kills" bson:"kills"`
+	Headshots int    `json:"headshots" bson:"headshots"`
+	Damage    int    `json:"damage" bson:"damage"`
+	Shots     int    `json:"shots" bson:"shots"`
+	Hits      int    `json:"hits" bson:"hits"`
+	// Accuracy describes the share of shots that hit a player.
+	Accuracy float32 `json:"accuracy" bson:"accuracy"`
+}
+
 func NewPlayer(id uint64) (*Player, error) {
 	p := &Player{
 		ID:        id,
@@ -59,6 +115,98 @@ func NewPlayer(id uint64) (*Player, error) {
 	return p, nil
 }
 
+// CalculateRatings calculates KAST, ADR and both ratings from the totals of the match.
+// The amount of match rounds has to be set before.
+func (r *PlayerResult) CalculateRatings() {
+	if r.MatchRounds == 0 {
+		return
+	}
+
+	rounds := float32(r.MatchRounds)
+	r.KAST = float32(r.KASTRounds) / rounds
+	r.ADR = float32(r.DamageDealt) / rounds
+
+	multiKills := [5]int{int(r.RoundsWith1K), int(r.RoundsWith2K), int(r.RoundsWith3K), int(r.RoundsWith4K), int(r.RoundsWith5K)}
+	r.Rating = RatingOne(int(r.Kills), int(r.Deaths), int(r.MatchRounds), multiKills)
+	r.RatingTwo = RatingTwo(r.KAST, float32(r.Kills)/rounds, float32(r.Deaths)/rounds, float32(r.Assists)/rounds, r.ADR)
+}
+
+// GetWeapon returns the result of the weapon with the given name and adds it if it does not exist yet.
+func (r *PlayerResult) GetWeapon(weapon string) *WeaponResult {
+	for _, weaponResult := range r.Weapons {
+		if weaponResult.Weapon == weapon {
+			return weaponResult
+		}
+	}
+
+	weaponResult := &WeaponResult{Weapon: weapon}
+	r.Weapons = append(r.Weapons, weaponResult)
+	return weaponResult
+}
+
+// CalculateAccuracy calculates the accuracy of all weapons from the shots and hits.
+func (r *PlayerResult) CalculateAccuracy() {
+	for _, weaponResult := range r.Weapons {
+		if weaponResult.Shots == 0 {
+			continue
+		}
+
+		weaponResult.Accuracy = float32(weaponResult.Hits) / float32(weaponResult.Shots)
+	}
+}
+
+// AddOpeningDuel adds an opening duel on the given side and counts it as entry kill if it was won.
+func (r *PlayerResult) AddOpeningDuel(side common.Team, won bool) {
+	r.OpeningDuelAttempts++
+	if won {
+		r.EntryKills++
+	}
+
+	switch side {
+	case common.TeamTerrorists:
+		r.OpeningDuelAttemptsAsT++
+		if won {
+			r.EntryKillsAsT++
+		}
+	case common.TeamCounterTerrorists:
+		r.OpeningDuelAttemptsAsCT++
+		if won {
+			r.EntryKillsAsCT++
+		}
+	}
+}
+
+// AddClutch adds a clutch attempt against the amount of opponents and counts it as won if the round was won.
+func (r *PlayerResult) AddClutch(opponents byte, won bool) {
+	switch opponents {
+	case 1:
+		r.Attempts1v1++
+		if won {
+			r.Won1v1++
+		}
+	case 2:
+		r.Attempts1v2++
+		if won {
+			r.Won1v2++
+		}
+	case 3:
+		r.Attempts1v3++
+		if won {
+			r.Won1v3++
+		}
+	case 4:
+		r.Attempts1v4++
+		if won {
+			r.Won1v4++
+		}
+	case 5:
+		r.Attempts1v5++
+		if won {
+			r.Won1v5++
+		}
+	}
+}
+
 func (p *Player) Validate() error {
 	err := validate.Struct(p)
 	if err != nil {
@@ -0,0 +1,43 @@
+package player_test
+
+import (
+	"testing"
+
+	"github.com/Cludch/csgo-tools/internal/domain/player"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddClutch(t *testing.T) {
+	r := &player.PlayerResult{}
+	r.AddClutch(1, true)
+	r.AddClutch(3, false)
+	r.AddClutch(3, true)
+	r.AddClutch(5, false)
+
+	assert.Equal(t, byte(1), r.Attempts1v1)
+	assert.Equal(t, byte(1), r.Won1v1)
+	assert.Equal(t, byte(2), r.Attempts1v3)
+	assert.Equal(t, byte(1), r.Won1v3)
+	assert.Equal(t, byte(1), r.Attempts1v5)
+	assert.Equal(t, byte(0), r.Won1v5)
+}
+
+func TestNewPlayerWeaponStats(t *testing.T) {
+	p := &player.Player{ID: 1, Results: []*player.PlayerResult{
+		{Name: "old", Weapons: []*player.WeaponResult{{Weapon: "AK-47", Kills: 2, Headshots: 1, Shots: 10, Hits: 4}}},
+		{Name: "new", Weapons: []*player.WeaponResult{
+			{Weapon: "Glock-18", Kills: 1, Shots: 5, Hits: 1},
+			{Weapon: "AK-47", Kills: 2, Headshots: 2, Shots: 10, Hits: 6},
+		}},
+	}}
+
+	stats := player.NewPlayerWeaponStats(p)
+
+	assert.Equal(t, "new", stats.Name)
+	assert.Len(t, stats.Weapons, 2)
+	assert.Equal(t, "AK-47", stats.Weapons[0].Weapon)
+	assert.Equal(t, 4, stats.Weapons[0].Kills)
+	assert.Equal(t, float32(0.75), stats.Weapons[0].HeadshotRate)
+	assert.Equal(t, float32(0.5), stats.Weapons[0].Accuracy)
+	assert.Equal(t, float32(0.2), stats.Weapons[1].Accuracy)
+}
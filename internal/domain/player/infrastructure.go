@@ -12,8 +12,6 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
-var ctx = context.TODO()
-
 type RepositoryMongo struct {
 	db *entity.Service
 }
@@ -26,30 +24,30 @@ func NewRepositoryMongo(db *entity.Service) *RepositoryMongo {
 	return r
 }
 
-func (r *RepositoryMongo) Create(m *Player) error {
+func (r *RepositoryMongo) Create(ctx context.Context, m *Player) error {
 	collection := r.getCollection()
 	_, err := collection.InsertOne(ctx, m)
 	return handleError(err)
 }
 
-func (r *RepositoryMongo) Find(id uint64) (*Player, error) {
+func (r *RepositoryMongo) Find(ctx context.Context, id uint64) (*Player, error) {
 	filterConfig := bson.M{"_id": id}
-	p, err := r.filterOne(filterConfig)
+	p, err := r.filterOne(ctx, filterConfig)
 	return p, handleError(err)
 }
 
-func (r *RepositoryMongo) FindByFaceitId(id entity.ID) (*Player, error) {
+func (r *RepositoryMongo) FindByFaceitId(ctx context.Context, id entity.ID) (*Player, error) {
 	filterConfig := bson.M{"faceitId": id}
-	return r.filterOne(filterConfig)
+	return r.filterOne(ctx, filterConfig)
 }
 
-func (r *RepositoryMongo) List() ([]*Player, error) {
+func (r *RepositoryMongo) List(ctx context.Context) ([]*Player, error) {
 	filterConfig := bson.M{}
-	p, err := r.filter(filterConfig)
+	p, err := r.filter(ctx, filterConfig)
 	return p, handleError(err)
 }
 
-func (r *RepositoryMongo) AddResult(p *Player, result *PlayerResult) error {
+func (r *RepositoryMongo) AddResult(ctx context.Context, p *Player, result *PlayerResult) error {
 	filter := bson.M{"_id": p.ID}
 
 	update := bson.D{primitive.E{Key: "$push", Value: bson.D{
@@ -60,7 +58,7 @@ func (r *RepositoryMongo) AddResult(p *Player, result *PlayerResult) error {
 	return handleError(r.getCollection().FindOneAndUpdate(ctx, filter, update).Decode(t))
 }
 
-func (r *RepositoryMongo) DeleteResult(p *Player, matchId entity.ID) error {
+func (r *RepositoryMongo) DeleteResult(ctx context.Context, p *Player, matchId entity.ID) error {
 	filter := bson.M{"_id": p.ID}
 
 	pull := bson.D{primitive.E{Key: "$pull", Value: bson.D{
@@ -71,11 +69,22 @@ func (r *RepositoryMongo) DeleteResult(p *Player, matchId entity.ID) error {
 	return handleError(r.getCollection().FindOneAndUpdate(ctx, filter, pull).Decode(t))
 }
 
+func (r *RepositoryMongo) DeleteMatchResults(ctx context.Context, matchId entity.ID) error {
+	filter := bson.M{"results.matchId": matchId}
+
+	pull := bson.D{primitive.E{Key: "$pull", Value: bson.D{
+		primitive.E{Key: "results", Value: bson.D{{Key: "matchId", Value: matchId}}},
+	}}}
+
+	_, err := r.getCollection().UpdateMany(ctx, filter, pull)
+	return handleError(err)
+}
+
 func (r *RepositoryMongo) getCollection() *mongo.Collection {
 	return r.db.GetCollection("players")
 }
 
-func (r *RepositoryMongo) filterOne(filter interface{}) (*Player, error) {
+func (r *RepositoryMongo) filterOne(ctx context.Context, filter interface{}) (*Player, error) {
 	var p *Player
 	res := r.getCollection().FindOne(ctx, filter)
 	if err := res.Decode(&p); err != nil {
@@ -85,7 +94,7 @@ func (r *RepositoryMongo) filterOne(filter interface{}) (*Player, error) {
 	return p, nil
 }
 
-func (r *RepositoryMongo) filter(filter interface{}) ([]*Player, error) {
+func (r *RepositoryMongo) filter(ctx context.Context, filter interface{}) ([]*Player, error) {
 	var players []*Player
 
 	cur, err := r.getCollection().Find(ctx, filter)
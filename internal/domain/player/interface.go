@@ -1,29 +1,33 @@
 package player
 
 import (
+	"context"
+
 	"github.com/Cludch/csgo-tools/internal/domain/entity"
 )
 
 type Repository interface {
-	Create(*Player) error
+	Create(context.Context, *Player) error
 
-	Find(uint64) (*Player, error)
+	Find(context.Context, uint64) (*Player, error)
 
-	List() ([]*Player, error)
+	List(context.Context) ([]*Player, error)
 
-	AddResult(*Player, *PlayerResult) error
+	AddResult(context.Context, *Player, *PlayerResult) error
 
-	DeleteResult(*Player, entity.ID) error
+	DeleteResult(context.Context, *Player, entity.ID) error
+	DeleteMatchResults(context.Context, entity.ID) error
 }
 
 type UseCase interface {
-	CreatePlayer(steamId uint64) (*Player, error)
+	CreatePlayer(ctx context.Context, steamId uint64) (*Player, error)
 
-	GetAll() ([]*Player, error)
-	GetPlayer(uint64) (*Player, error)
+	GetAll(context.Context) ([]*Player, error)
+	GetPlayer(context.Context, uint64) (*Player, error)
 	GetResult(p *Player, matchId entity.ID) (*PlayerResult, error)
 
-	AddResult(*Player, *PlayerResult) error
+	AddResult(context.Context, *Player, *PlayerResult) error
 
-	DeleteResult(p *Player, matchId entity.ID) error
+	DeleteResult(ctx context.Context, p *Player, matchId entity.ID) error
+	DeleteMatchResults(ctx context.Context, matchId entity.ID) error
 }
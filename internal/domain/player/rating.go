@@ -0,0 +1,59 @@
+package player
+
+// Average values of the HLTV rating 1.0 used to normalize the single ratings.
+const (
+	averageKPR             = 0.679
+	averageSPR             = 0.317
+	averageRMK             = 1.277
+	survivalRatingWeight   = 0.7
+	ratingOneNormalization = 2.7
+	percent                = 100
+	ratingTwoKASTWeight    = 0.0073
+	ratingTwoKPRWeight     = 0.3591
+	ratingTwoDPRWeight     = -0.5329
+	ratingTwoImpactWeight  = 0.2372
+	ratingTwoADRWeight     = 0.0032
+	ratingTwoIntercept     = 0.1587
+	impactKPRWeight        = 2.13
+	impactAPRWeight        = 0.42
+	impactIntercept        = -0.41
+)
+
+// RatingOne calculates the HLTV rating 1.0.
+// multiKills contains the amount of rounds with one to five kills.
+//
+// Rating = (KillRating + 0.7 * SurvivalRating + RoundsWithMultipleKillsRating) / 2.7
+//
+//	KillRating = Kills / Rounds / 0.679
+//	SurvivalRating = (Rounds - Deaths) / Rounds / 0.317
+//	RoundsWithMultipleKillsRating = (1K + 4 * 2K + 9 * 3K + 16 * 4K + 25 * 5K) / Rounds / 1.277
+func RatingOne(kills int, deaths int, rounds int, multiKills [5]int) float32 {
+	if rounds == 0 {
+		return 0
+	}
+
+	r := float64(rounds)
+	killRating := float64(kills) / r / averageKPR
+	survivalRating := float64(rounds-deaths) / r / averageSPR
+
+	multiKillScore := 0
+	for index, amount := range multiKills {
+		multiKillScore += (index + 1) * (index + 1) * amount
+	}
+	multiKillRating := float64(multiKillScore) / r / averageRMK
+
+	return float32((killRating + survivalRatingWeight*survivalRating + multiKillRating) / ratingOneNormalization)
+}
+
+// RatingTwo approximates the HLTV rating 2.0 as the exact formula is not public.
+// kast is the share of rounds with a kill, assist, survival or trade between 0 and 1.
+//
+// Rating = 0.0073 * KAST + 0.3591 * KPR - 0.5329 * DPR + 0.2372 * Impact + 0.0032 * ADR + 0.1587
+//
+//	Impact = 2.13 * KPR + 0.42 * APR - 0.41
+func RatingTwo(kast float32, kpr float32, dpr float32, apr float32, adr float32) float32 {
+	impact := impactKPRWeight*kpr + impactAPRWeight*apr + impactIntercept
+
+	return ratingTwoKASTWeight*kast*percent + ratingTwoKPRWeight*kpr + ratingTwoDPRWeight*dpr +
+		ratingTwoImpactWeight*impact + ratingTwoADRWeight*adr + ratingTwoIntercept
+}
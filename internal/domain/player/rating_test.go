@@ -0,0 +1,33 @@
+package player_test
+
+import (
+	"testing"
+
+	"github.com/Cludch/csgo-tools/internal/domain/player"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRatingOne(t *testing.T) {
+	// 20 kills and 15 deaths in 25 rounds with 8 single kill and 6 double kill rounds.
+	rating := player.RatingOne(20, 15, 25, [5]int{8, 6, 0, 0, 0})
+	assert.InDelta(t, 1.13, rating, 0.01)
+
+	assert.Equal(t, float32(0), player.RatingOne(0, 0, 0, [5]int{}))
+}
+
+func TestRatingTwo(t *testing.T) {
+	// Average values of 72% KAST, 0.68 KPR and DPR, 0.13 APR and 76 ADR. The approximated weights rate them at 1.07
+	// instead of exactly 1.0, the delta only allows for rounding.
+	rating := player.RatingTwo(0.72, 0.68, 0.68, 0.13, 76)
+	assert.InDelta(t, 1.07, rating, 0.01)
+}
+
+func TestCalculateRatings(t *testing.T) {
+	r := &player.PlayerResult{MatchRounds: 20, Kills: 20, Deaths: 10, DamageDealt: 2000, KASTRounds: 15, RoundsWith1K: 10, RoundsWith2K: 5}
+	r.CalculateRatings()
+
+	assert.Equal(t, float32(100), r.ADR)
+	assert.Equal(t, float32(0.75), r.KAST)
+	assert.Greater(t, r.Rating, float32(1))
+	assert.Greater(t, r.RatingTwo, float32(1))
+}
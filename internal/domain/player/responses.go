@@ -1,5 +1,11 @@
 package player
 
+import (
+	"sort"
+
+	"github.com/Cludch/csgo-tools/pkg/util"
+)
+
 type PlayerList struct {
 	Players []*PlayerListEntry `json:"players"`
 }
@@ -20,11 +26,39 @@ type PlayerGameStats struct {
 	KillsPerRound              float32 `json:"killsPerRound"`
 	EntryKillsPerRound         float32 `json:"entryKillsPerRound"`
 	OpeningDuelAttempsPerRound float32 `json:"openingDuelAttemptsPerRound"`
+	EntrySuccessRate           float32 `json:"entrySuccessRate"`
+	EntrySuccessRateAsT        float32 `json:"entrySuccessRateAsT"`
+	EntrySuccessRateAsCT       float32 `json:"entrySuccessRateAsCT"`
 	HeadshotsPerRound          float32 `json:"headshotsPerRound"`
 	AssistsPerRound            float32 `json:"assistsPerRound"`
 	DeathsPerRound             float32 `json:"deathsPerRound"`
 	MVPsPerRound               float32 `json:"mvpsPerRound"`
 	DamagePerRound             float32 `json:"damagePerRound"`
+	KAST                       float32 `json:"kast"`
+	Rating                     float32 `json:"rating"`
+	RatingTwo                  float32 `json:"ratingTwo"`
+	EquipmentValuePerRound     float32 `json:"equipmentValuePerRound"`
+	MoneySpentPerRound         float32 `json:"moneySpentPerRound"`
+	FlashesThrownPerRound      float32 `json:"flashesThrownPerRound"`
+	SmokesThrownPerRound       float32 `json:"smokesThrownPerRound"`
+	HEsThrownPerRound          float32 `json:"hesThrownPerRound"`
+	MolotovsThrownPerRound     float32 `json:"molotovsThrownPerRound"`
+	EnemiesFlashedPerRound     float32 `json:"enemiesFlashedPerRound"`
+	TeammatesFlashedPerRound   float32 `json:"teammatesFlashedPerRound"`
+	BlindSecondsPerRound       float32 `json:"blindSecondsPerRound"`
+	FlashAssistsPerRound       float32 `json:"flashAssistsPerRound"`
+	UtilityDamagePerRound      float32 `json:"utilityDamagePerRound"`
+	EcoWinRate                 float32 `json:"ecoWinRate"`
+	HalfBuyWinRate             float32 `json:"halfBuyWinRate"`
+	ForceBuyWinRate            float32 `json:"forceBuyWinRate"`
+	FullBuyWinRate             float32 `json:"fullBuyWinRate"`
+	Attempts1v1                int     `json:"attempts1v1"`
+	Attempts1v2                int     `json:"attempts1v2"`
+	Attempts1v3                int     `json:"attempts1v3"`
+	Attempts1v4                int     `json:"attempts1v4"`
+	Attempts1v5                int     `json:"attempts1v5"`
+	Won1v1                     int     `json:"won1v1"`
+	Won1v2                     int     `json:"won1v2"`
 	Won1v3                     int     `json:"won1v3"`
 	Won1v4                     int     `json:"won1v4"`
 	Won1v5                     int     `json:"won1v5"`
@@ -32,3 +66,57 @@ type PlayerGameStats struct {
 	RoundsWith4K               int     `json:"roundsWith4k"`
 	RoundsWith5K               int     `json:"roundsWith5k"`
 }
+
+// PlayerWeaponStats describes the performance of a player per weapon across all matches.
+type PlayerWeaponStats struct {
+	SteamID uint64         `json:"id"`
+	Name    string         `json:"name"`
+	Weapons []*WeaponStats `json:"weapons"`
+}
+
+// WeaponStats describes the summed up performance with one weapon.
+type WeaponStats struct {
+	Weapon       string  `json:"weapon"`
+	Kills        int     `json:"kills"`
+	Headshots    int     `json:"headshots"`
+	HeadshotRate float32 `json:"headshotRate"`
+	Damage       int     `json:"damage"`
+	Shots        int     `json:"shots"`
+	Hits         int     `json:"hits"`
+	Accuracy     float32 `json:"accuracy"`
+}
+
+// NewPlayerWeaponStats sums up the weapon results of all matches of the player.
+// The weapons are sorted by kills.
+func NewPlayerWeaponStats(p *Player) *PlayerWeaponStats {
+	stats := &PlayerWeaponStats{SteamID: p.ID, Weapons: make([]*WeaponStats, 0)}
+	weapons := make(map[string]*WeaponStats)
+
+	for _, playerResult := range p.Results {
+		stats.Name = playerResult.Name
+
+		for _, weaponResult := range playerResult.Weapons {
+			weapon, ok := weapons[weaponResult.Weapon]
+			if !ok {
+				weapon = &WeaponStats{Weapon: weaponResult.Weapon}
+				weapons[weaponResult.Weapon] = weapon
+				stats.Weapons = append(stats.Weapons, weapon)
+			}
+
+			weapon.Kills += weaponResult.Kills
+			weapon.Headshots += weaponResult.Headshots
+			weapon.Damage += weaponResult.Damage
+			weapon.Shots += weaponResult.Shots
+			weapon.Hits += weaponResult.Hits
+		}
+	}
+
+	for _, weapon := range stats.Weapons {
+		weapon.HeadshotRate = util.Rate(weapon.Headshots, weapon.Kills)
+		weapon.Accuracy = util.Rate(weapon.Hits, weapon.Shots)
+	}
+
+	sort.SliceStable(stats.Weapons, func(i, j int) bool { return stats.Weapons[i].Kills > stats.Weapons[j].Kills })
+
+	return stats
+}
@@ -1,6 +1,7 @@
 package player
 
 import (
+	"context"
 	"errors"
 
 	"github.com/Cludch/csgo-tools/internal/domain/entity"
@@ -16,19 +17,19 @@ func NewService(r Repository) *Service {
 	}
 }
 
-func (s *Service) CreatePlayer(id uint64) (*Player, error) {
+func (s *Service) CreatePlayer(ctx context.Context, id uint64) (*Player, error) {
 	p, _ := NewPlayer(id)
-	return p, s.repo.Create(p)
+	return p, s.repo.Create(ctx, p)
 }
 
-func (s *Service) GetAll() ([]*Player, error) {
-	return s.repo.List()
+func (s *Service) GetAll(ctx context.Context) ([]*Player, error) {
+	return s.repo.List(ctx)
 }
 
-func (s *Service) GetPlayer(id uint64) (*Player, error) {
-	p, err := s.repo.Find(id)
+func (s *Service) GetPlayer(ctx context.Context, id uint64) (*Player, error) {
+	p, err := s.repo.Find(ctx, id)
 	if p == nil {
-		return s.CreatePlayer(id)
+		return s.CreatePlayer(ctx, id)
 	}
 
 	return p, err
@@ -44,7 +45,7 @@ func (s *Service) GetResult(p *Player, matchId entity.ID) (*PlayerResult, error)
 	return nil, entity.ErrNotFound
 }
 
-func (s *Service) AddResult(p *Player, r *PlayerResult) error {
+func (s *Service) AddResult(ctx context.Context, p *Player, r *PlayerResult) error {
 	matchId := r.MatchID
 
 	// Delete old result.
@@ -54,16 +55,21 @@ func (s *Service) AddResult(p *Player, r *PlayerResult) error {
 	}
 
 	if dbResult != nil {
-		err = s.DeleteResult(p, matchId)
+		err = s.DeleteResult(ctx, p, matchId)
 		if err != nil {
 			return err
 		}
 	}
 
 	p.Results = append(p.Results, r)
-	return s.repo.AddResult(p, r)
+	return s.repo.AddResult(ctx, p, r)
 }
 
-func (s *Service) DeleteResult(p *Player, matchId entity.ID) error {
-	return s.repo.DeleteResult(p, matchId)
+func (s *Service) DeleteResult(ctx context.Context, p *Player, matchId entity.ID) error {
+	return s.repo.DeleteResult(ctx, p, matchId)
+}
+
+// DeleteMatchResults deletes the results of a match from all players.
+func (s *Service) DeleteMatchResults(ctx context.Context, matchId entity.ID) error {
+	return s.repo.DeleteMatchResults(ctx, matchId)
 }
@@ -14,8 +14,6 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-var ctx = context.TODO()
-
 type RepositoryMongo struct {
 	db *entity.Service
 }
@@ -50,51 +48,51 @@ func (r *RepositoryMongo) createIndex() {
 	}
 }
 
-func (r *RepositoryMongo) Create(u *User) error {
+func (r *RepositoryMongo) Create(ctx context.Context, u *User) error {
 	collection := r.getCollection()
 	_, err := collection.InsertOne(ctx, u)
 	return handleError(err)
 }
 
-func (r *RepositoryMongo) Find(id entity.ID) (*User, error) {
+func (r *RepositoryMongo) Find(ctx context.Context, id entity.ID) (*User, error) {
 	filterConfig := bson.M{"_id": id}
-	u, err := r.filterOne(filterConfig)
+	u, err := r.filterOne(ctx, filterConfig)
 	return u, handleError(err)
 }
 
-func (r *RepositoryMongo) FindBySteamId(id uint64) (*User, error) {
+func (r *RepositoryMongo) FindBySteamId(ctx context.Context, id uint64) (*User, error) {
 	filterConfig := bson.M{"steam.id": id}
-	u, err := r.filterOne(filterConfig)
+	u, err := r.filterOne(ctx, filterConfig)
 	return u, handleError(err)
 }
 
-func (r *RepositoryMongo) FindByFaceitId(id entity.ID) (*User, error) {
+func (r *RepositoryMongo) FindByFaceitId(ctx context.Context, id entity.ID) (*User, error) {
 	filterConfig := bson.M{"faceit.id": id}
-	u, err := r.filterOne(filterConfig)
+	u, err := r.filterOne(ctx, filterConfig)
 	return u, handleError(err)
 }
 
-func (r *RepositoryMongo) FindUsersContainingAuthenticationCode() ([]*User, error) {
+func (r *RepositoryMongo) FindUsersContainingAuthenticationCode(ctx context.Context) ([]*User, error) {
 	filterConfig := bson.M{"steam.apiEnabled": true}
-	u, err := r.filter(filterConfig)
+	u, err := r.filter(ctx, filterConfig)
 	return u, handleError(err)
 }
 
-func (r *RepositoryMongo) FindUsersContainingFaceitId() ([]*User, error) {
+func (r *RepositoryMongo) FindUsersContainingFaceitId(ctx context.Context) ([]*User, error) {
 	filterConfig := bson.D{primitive.E{Key: "faceit.id", Value: bson.D{
 		primitive.E{Key: "$exists", Value: true},
 	}}}
-	u, err := r.filter(filterConfig)
+	u, err := r.filter(ctx, filterConfig)
 	return u, handleError(err)
 }
 
-func (r *RepositoryMongo) List() ([]*User, error) {
+func (r *RepositoryMongo) List(ctx context.Context) ([]*User, error) {
 	filterConfig := bson.M{}
-	u, err := r.filter(filterConfig)
+	u, err := r.filter(ctx, filterConfig)
 	return u, handleError(err)
 }
 
-func (r *RepositoryMongo) Delete(id entity.ID) error {
+func (r *RepositoryMongo) Delete(ctx context.Context, id entity.ID) error {
 	filter := bson.M{"_id": id}
 
 	res, err := r.getCollection().DeleteOne(ctx, filter)
@@ -109,7 +107,7 @@ func (r *RepositoryMongo) Delete(id entity.ID) error {
 	return nil
 }
 
-func (r *RepositoryMongo) UpdateMatchAuthCode(u *User) error {
+func (r *RepositoryMongo) UpdateMatchAuthCode(ctx context.Context, u *User) error {
 	filter := bson.M{"_id": u.ID}
 
 	update := bson.D{primitive.E{Key: "$set", Value: bson.D{
@@ -120,7 +118,7 @@ func (r *RepositoryMongo) UpdateMatchAuthCode(u *User) error {
 	return handleError(r.getCollection().FindOneAndUpdate(ctx, filter, update).Decode(t))
 }
 
-func (r *RepositoryMongo) UpdateLatestShareCode(u *User) error {
+func (r *RepositoryMongo) UpdateLatestShareCode(ctx context.Context, u *User) error {
 	filter := bson.M{"_id": u.ID}
 
 	update := bson.D{primitive.E{Key: "$set", Value: bson.D{
@@ -131,7 +129,7 @@ func (r *RepositoryMongo) UpdateLatestShareCode(u *User) error {
 	return handleError(r.getCollection().FindOneAndUpdate(ctx, filter, update).Decode(t))
 }
 
-func (r *RepositoryMongo) UpdateSteamAPIUsage(u *User) error {
+func (r *RepositoryMongo) UpdateSteamAPIUsage(ctx context.Context, u *User) error {
 	filter := bson.M{"_id": u.ID}
 
 	update := bson.D{primitive.E{Key: "$set", Value: bson.D{
@@ -146,7 +144,7 @@ func (r *RepositoryMongo) getCollection() *mongo.Collection {
 	return r.db.GetCollection("users")
 }
 
-func (r *RepositoryMongo) filterOne(filter interface{}) (*User, error) {
+func (r *RepositoryMongo) filterOne(ctx context.Context, filter interface{}) (*User, error) {
 	var u *User
 	res := r.getCollection().FindOne(ctx, filter)
 	if err := res.Decode(&u); err != nil {
@@ -156,7 +154,7 @@ func (r *RepositoryMongo) filterOne(filter interface{}) (*User, error) {
 	return u, nil
 }
 
-func (r *RepositoryMongo) filter(filter interface{}) ([]*User, error) {
+func (r *RepositoryMongo) filter(ctx context.Context, filter interface{}) ([]*User, error) {
 	var users []*User
 
 	cur, err := r.getCollection().Find(ctx, filter)
@@ -1,46 +1,49 @@
 package user
 
 import (
+	"context"
+
 	"github.com/Cludch/csgo-tools/internal/domain/entity"
 	"github.com/Cludch/csgo-tools/pkg/share_code"
 )
 
 // Repository defines repository functions for user entities.
 type Repository interface {
-	Create(*User) error
+	Create(context.Context, *User) error
 
-	Find(entity.ID) (*User, error)
-	FindUsersContainingAuthenticationCode() ([]*User, error)
-	FindUsersContainingFaceitId() ([]*User, error)
-	FindBySteamId(uint64) (*User, error)
-	FindByFaceitId(entity.ID) (*User, error)
+	Find(context.Context, entity.ID) (*User, error)
+	FindUsersContainingAuthenticationCode(context.Context) ([]*User, error)
+	FindUsersContainingFaceitId(context.Context) ([]*User, error)
+	FindBySteamId(context.Context, uint64) (*User, error)
+	FindByFaceitId(context.Context, entity.ID) (*User, error)
 
-	List() ([]*User, error)
+	List(context.Context) ([]*User, error)
 
-	UpdateLatestShareCode(*User) error
-	UpdateMatchAuthCode(u *User) error
-	UpdateSteamAPIUsage(*User) error
+	UpdateLatestShareCode(context.Context, *User) error
+	UpdateMatchAuthCode(ctx context.Context, u *User) error
+	UpdateSteamAPIUsage(context.Context, *User) error
 
-	Delete(entity.ID) error
+	Delete(context.Context, entity.ID) error
 }
 
 // UseCase defines the user service functions.
 type UseCase interface {
-	CreateUserUsingSteam(id uint64, nickname string) (*User, error)
-	CreateUserUsingFaceit(id entity.ID, nickname string) (*User, error)
+	CreateUserUsingSteam(ctx context.Context, id uint64, nickname string) (*User, error)
+	CreateUserUsingFaceit(ctx context.Context, id entity.ID, nickname string) (*User, error)
 
-	GetUser(entity.ID) (*User, error)
-	GetUserBySteamId(uint64) (*User, error)
-	GetUserByFaceitId(entity.ID) (*User, error)
-	GetUsersWithAuthenticationCode() ([]*User, error)
-	GetUsersWithFaceitId() ([]*User, error)
+	GetAll(context.Context) ([]*User, error)
+	GetUser(context.Context, entity.ID) (*User, error)
+	GetUserBySteamId(context.Context, uint64) (*User, error)
+	GetUserByFaceitId(context.Context, entity.ID) (*User, error)
+	GetUsersWithAuthenticationCode(context.Context) ([]*User, error)
+	GetUsersWithFaceitId(context.Context) ([]*User, error)
 
-	AddSteamMatchHistoryAuthenticationCode(user *User, authCode string, sc string) error
-	UpdateSteamAPIUsage(*User, bool) error
-	UpdateLatestShareCode(*User, *share_code.ShareCodeData) error
+	AddSteamMatchHistoryAuthenticationCode(ctx context.Context, user *User, authCode string, sc string) error
+	UpdateSteamAPIUsage(context.Context, *User, bool) error
+	UpdateLatestShareCode(context.Context, *User, *share_code.ShareCodeData) error
 
-	SigninUsingSteam(uint64, string) (*User, error)
-	SigninUsingFaceit(entity.ID, string) (*User, error)
+	SigninUsingSteam(context.Context, uint64, string) (*User, error)
+	SigninUsingFaceit(context.Context, entity.ID, string) (*User, error)
 
-	QueryLatestShareCode(*User) (*share_code.ShareCodeData, error)
+	QueryLatestShareCode(context.Context, *User) (*share_code.ShareCodeData, error)
 }
@@ -1,6 +1,7 @@
 package user
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -25,32 +26,36 @@ func NewService(r Repository, c config.UseCase) *Service {
 	}
 }
 
-func (s *Service) GetUser(id entity.ID) (*User, error) {
-	return s.repo.Find(id)
+func (s *Service) GetAll(ctx context.Context) ([]*User, error) {
+	return s.repo.List(ctx)
 }
 
-func (s *Service) GetUserBySteamId(id uint64) (*User, error) {
-	return s.repo.FindBySteamId(id)
+func (s *Service) GetUser(ctx context.Context, id entity.ID) (*User, error) {
+	return s.repo.Find(ctx, id)
 }
-func (s *Service) GetUserByFaceitId(id entity.ID) (*User, error) {
-	return s.repo.FindByFaceitId(id)
+
+func (s *Service) GetUserBySteamId(ctx context.Context, id uint64) (*User, error) {
+	return s.repo.FindBySteamId(ctx, id)
+}
+func (s *Service) GetUserByFaceitId(ctx context.Context, id entity.ID) (*User, error) {
+	return s.repo.FindByFaceitId(ctx, id)
 }
 
-func (s *Service) GetUsersWithAuthenticationCode() ([]*User, error) {
-	return s.repo.FindUsersContainingAuthenticationCode()
+func (s *Service) GetUsersWithAuthenticationCode(ctx context.Context) ([]*User, error) {
+	return s.repo.FindUsersContainingAuthenticationCode(ctx)
 }
 
-func (s *Service) GetUsersWithFaceitId() ([]*User, error) {
-	return s.repo.FindUsersContainingFaceitId()
+func (s *Service) GetUsersWithFaceitId(ctx context.Context) ([]*User, error) {
+	return s.repo.FindUsersContainingFaceitId(ctx)
 }
 
-func (s *Service) CreateUserUsingSteam(id uint64, nickname string) (*User, error) {
+func (s *Service) CreateUserUsingSteam(ctx context.Context, id uint64, nickname string) (*User, error) {
 	u, err := NewUserUsingSteam(id, nickname)
 	if err != nil {
 		return nil, err
 	}
 
-	err = s.createUser(u)
+	err = s.createUser(ctx, u)
 	if err != nil {
 		return nil, err
 	}
@@ -58,13 +63,13 @@ func (s *Service) CreateUserUsingSteam(id uint64, nickname string) (*User, error
 	return u, nil
 }
 
-func (s *Service) CreateUserUsingFaceit(id entity.ID, nickname string) (*User, error) {
+func (s *Service) CreateUserUsingFaceit(ctx context.Context, id entity.ID, nickname string) (*User, error) {
 	u, err := NewUserUsingFaceit(id, nickname)
 	if err != nil {
 		return nil, err
 	}
 
-	err = s.createUser(u)
+	err = s.createUser(ctx, u)
 	if err != nil {
 		return nil, err
 	}
@@ -72,34 +77,34 @@ func (s *Service) CreateUserUsingFaceit(id entity.ID, nickname string) (*User, e
 	return u, nil
 }
 
-func (s *Service) createUser(u *User) error {
-	dbUser, _ := s.GetUser(u.ID)
+func (s *Service) createUser(ctx context.Context, u *User) error {
+	dbUser, _ := s.GetUser(ctx, u.ID)
 	if dbUser != nil {
 		return errors.New("user with id already exists")
 	}
 
 	if u.Steam != nil {
-		steamUser, _ := s.GetUserBySteamId(u.Steam.ID)
+		steamUser, _ := s.GetUserBySteamId(ctx, u.Steam.ID)
 		if steamUser != nil {
 			return errors.New("user with steam id already exists")
 		}
 	}
 
 	if u.Faceit != nil {
-		faceitUser, _ := s.GetUserByFaceitId(u.Faceit.ID)
+		faceitUser, _ := s.GetUserByFaceitId(ctx, u.Faceit.ID)
 		if faceitUser != nil {
 			return errors.New("user with faceit id already exists")
 		}
 	}
 
-	return s.repo.Create(u)
+	return s.repo.Create(ctx, u)
 }
 
-func (s *Service) AddSteamMatchHistoryAuthenticationCode(user *User, authCode string, sc string) error {
+func (s *Service) AddSteamMatchHistoryAuthenticationCode(ctx context.Context, user *User, authCode string, sc string) error {
 	shareCode, _ := share_code.Decode(sc)
 
 	// Test credentials
-	_, errTest := valveapi.GetNextMatch(s.configurationService.GetConfig().Steam.SteamAPIKey, user.Steam.ID, authCode, sc)
+	_, errTest := valveapi.GetNextMatch(ctx, s.configurationService.GetConfig().Steam.SteamAPIKey, user.Steam.ID, authCode, sc)
 	if errTest != nil {
 		return errors.New("invalid authentication code or last share code")
 	}
@@ -109,17 +114,17 @@ func (s *Service) AddSteamMatchHistoryAuthenticationCode(user *User, authCode st
 		return err
 	}
 
-	errAdd := s.repo.UpdateMatchAuthCode(user)
+	errAdd := s.repo.UpdateMatchAuthCode(ctx, user)
 	if errAdd != nil {
 		return errAdd
 	}
 
-	errSc := s.repo.UpdateLatestShareCode(user)
+	errSc := s.repo.UpdateLatestShareCode(ctx, user)
 	if errSc != nil {
 		return errSc
 	}
 
-	errApi := s.UpdateSteamAPIUsage(user, true)
+	errApi := s.UpdateSteamAPIUsage(ctx, user, true)
 	if errApi != nil {
 		return errApi
 	}
@@ -127,26 +132,26 @@ func (s *Service) AddSteamMatchHistoryAuthenticationCode(user *User, authCode st
 	return nil
 }
 
-func (s *Service) UpdateSteamAPIUsage(u *User, active bool) error {
+func (s *Service) UpdateSteamAPIUsage(ctx context.Context, u *User, active bool) error {
 	if active && u.Steam.AuthCode == "" {
 		return errors.New("missing steam api auth code")
 	}
 
 	u.Steam.APIEnabled = active
-	return s.repo.UpdateSteamAPIUsage(u)
+	return s.repo.UpdateSteamAPIUsage(ctx, u)
 }
-func (s *Service) UpdateLatestShareCode(u *User, sc *share_code.ShareCodeData) error {
+func (s *Service) UpdateLatestShareCode(ctx context.Context, u *User, sc *share_code.ShareCodeData) error {
 	u.Steam.LastShareCode = sc.Encoded
-	return s.repo.UpdateLatestShareCode(u)
+	return s.repo.UpdateLatestShareCode(ctx, u)
 }
 
-func (s *Service) SigninUsingSteam(id uint64, nickname string) (*User, error) {
+func (s *Service) SigninUsingSteam(ctx context.Context, id uint64, nickname string) (*User, error) {
 	log.Debugf("Attempting sign in using steam for user %d (%s)", id, nickname)
-	user, err := s.repo.FindBySteamId(id)
+	user, err := s.repo.FindBySteamId(ctx, id)
 	if err != nil {
 		if errors.Is(err, entity.ErrNotFound) {
 			log.Debugf("no user with id %d found. creating a new one..", id)
-			return s.CreateUserUsingSteam(id, nickname)
+			return s.CreateUserUsingSteam(ctx, id, nickname)
 		}
 
 		return nil, err
@@ -155,13 +160,13 @@ func (s *Service) SigninUsingSteam(id uint64, nickname string) (*User, error) {
 	return user, nil
 }
 
-func (s *Service) SigninUsingFaceit(id entity.ID, nickname string) (*User, error) {
+func (s *Service) SigninUsingFaceit(ctx context.Context, id entity.ID, nickname string) (*User, error) {
 	log.Debugf("Attempting sign in using faceit for user %v (%s)", id, nickname)
-	user, err := s.repo.FindByFaceitId(id)
+	user, err := s.repo.FindByFaceitId(ctx, id)
 	if err != nil {
 		if errors.Is(err, entity.ErrNotFound) {
 			log.Debugf("no user with id %v found. creating a new one..", id)
-			return s.CreateUserUsingFaceit(id, nickname)
+			return s.CreateUserUsingFaceit(ctx, id, nickname)
 		}
 
 		return nil, err
@@ -170,13 +175,13 @@ func (s *Service) SigninUsingFaceit(id entity.ID, nickname string) (*User, error
 	return user, nil
 }
 
-func (s *Service) QueryLatestShareCode(u *User) (*share_code.ShareCodeData, error) {
+func (s *Service) QueryLatestShareCode(ctx context.Context, u *User) (*share_code.ShareCodeData, error) {
 	if !u.Steam.APIEnabled {
 		return nil, errors.New("user: api usage is disabled")
 	}
 
 	steamID := u.Steam.ID
-	shareCode, err := valveapi.GetNextMatch(s.configurationService.GetConfig().Steam.SteamAPIKey, steamID, u.Steam.AuthCode, u.Steam.LastShareCode)
+	shareCode, err := valveapi.GetNextMatch(ctx, s.configurationService.GetConfig().Steam.SteamAPIKey, steamID, u.Steam.AuthCode, u.Steam.LastShareCode)
 
 	// Disable user on error.
 	if err != nil {
@@ -189,7 +194,7 @@ func (s *Service) QueryLatestShareCode(u *User) (*share_code.ShareCodeData, erro
 
 		/*
 			// TODO Issue #64
-			updateErr := s.UpdateSteamAPIUsage(u, false)
+			updateErr := s.UpdateSteamAPIUsage(ctx, u, false)
 			if updateErr != nil {
 				const msg = "disabled csgo user %d due to an error (%t) in fetching the share code"
 				log.Warnf(msg, steamID, err)
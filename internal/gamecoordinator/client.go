@@ -1,6 +1,7 @@
 package gamecoordinator
 
 import (
+	"context"
 	"time"
 
 	log "github.com/sirupsen/logrus"
@@ -16,6 +17,8 @@ type HandlerMap map[uint32]func(packet *gamecoordinator.GCPacket)
 
 // GC holds the steam client and whether the client is connected to the GameCoordinator
 type GC struct {
+	// ctx is cancelled once the connection is closed.
+	ctx         context.Context
 	client      *steam.Client
 	isConnected bool
 	handlers    HandlerMap
@@ -28,8 +31,8 @@ type GCReadyEvent struct{}
 const AppID = 730
 
 // NewCSGO creates a CS client from a steam client and registers the packet handler
-func (s *Service) Connect(client *steam.Client) {
-	s.gc = &GC{client: client, isConnected: false}
+func (s *Service) Connect(ctx context.Context, client *steam.Client) {
+	s.gc = &GC{ctx: ctx, client: client, isConnected: false}
 	s.BuildHandlerMap()
 	s.gc.client.GC.RegisterPacketHandler(s)
 	s.SetPlaying(true)
@@ -1,16 +1,23 @@
 package gamecoordinator
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
 	"time"
 
 	log "github.com/sirupsen/logrus"
 
+	"github.com/Cludch/csgo-tools/internal/domain/entity"
+	"github.com/Cludch/csgo-tools/internal/domain/job"
+	"github.com/Cludch/csgo-tools/internal/domain/match"
 	csgo "github.com/Philipp15b/go-steam/v2/csgo/protocol/protobuf"
 	"github.com/Philipp15b/go-steam/v2/protocol/gamecoordinator"
 )
 
-// Channel is used to request demos one after another.
-var matchResponse chan bool = make(chan bool, 1)
+// Timeout of a request for match details.
+const requestTimeout = 15 * time.Second
 
 // HandleMatchList handles a gc message containing matches and tries to download those.
 func (s *Service) HandleMatchList(packet *gamecoordinator.GCPacket) {
@@ -27,51 +34,112 @@ func (s *Service) HandleMatchList(packet *gamecoordinator.GCPacket) {
 
 			id := round.GetReservationid()
 			time := time.Unix(int64(*matchEntry.Matchtime), 0)
-			err := s.matchService.UpdateDownloadInformationForOutcomeId(id, time, round.GetMap())
+			m, err := s.matchService.UpdateDownloadInformationForOutcomeId(s.gc.ctx, id, time, round.GetMap())
 			if err != nil {
 				const msg = "gamecoordinator: %s"
 				log.Errorf(msg, err)
-			} else {
-				const msg = "gamecoordinator: saved match details for %d"
-				log.Infof(msg, id)
+				continue
+			}
+
+			const msg = "gamecoordinator: saved match details for %d"
+			log.Infof(msg, id)
+
+			if err := s.jobService.Enqueue(s.gc.ctx, job.Download, m.ID, s.configurationService.GetDownloadConfig().MaxAttempts); err != nil {
+				log.Error(err)
 			}
 		}
-	}
 
-	matchResponse <- true
+		s.resolveLookup(matchEntry.GetMatchid())
+	}
 }
 
-// HandleGCReady starts a daemon and takes non-downloaded share codes from the database.
-func (s *Service) HandleGCReady(e *GCReadyEvent) {
-	// Request demos for non-processed share codes from the database
-	t := time.NewTicker(time.Minute * 5)
-	for {
-		matches, err := s.matchService.GetValveMatchesMissingDownloadUrl()
-		if err != nil {
+// HandleGCReady enqueues matches missing their download url and starts requesting their details from the
+// GameCoordinator one after another until the context is cancelled.
+func (s *Service) HandleGCReady(ctx context.Context, e *GCReadyEvent) {
+	matches, err := s.matchService.GetValveMatchesMissingDownloadUrl(ctx)
+	if err != nil {
+		log.Error(err)
+	}
+
+	for _, m := range matches {
+		if err := s.jobService.Enqueue(ctx, job.GCLookup, m.ID, job.DefaultMaxAttempts); err != nil {
 			log.Error(err)
 		}
+	}
 
-		// Request demo after another and timeout a request after 5 seconds.
-		ch := make(chan bool, 1)
-		for _, m := range matches {
-			matchResponse = make(chan bool)
-			sc := m.ShareCode
-			go s.RequestMatch(sc)
-			select {
-			case <-matchResponse:
-				const msg = "gamecoordinator: received response for %s"
-				log.Debugf(msg, sc.Encoded)
-				ch <- true
-			case <-time.After(15 * time.Second):
-				const msg = "gamecoordinator: failed to receive response for %s"
-				log.Fatalf(msg, sc.Encoded)
-				ch <- false
-			}
+	owner := job.NewOwner(fmt.Sprintf("gc-%d", atomic.AddUint64(&s.sessions, 1)))
+	s.jobService.Run(ctx, job.GCLookup, owner, s.lookupMatch, lookupBackoff)
+}
 
-			<-ch
+// Returns the delay before retrying a failed request.
+func lookupBackoff(attempts int) time.Duration {
+	return time.Duration(attempts) * 5 * time.Minute
+}
+
+// Requests the details of the match of the job and waits for the response.
+func (s *Service) lookupMatch(ctx context.Context, j *job.Job) error {
+	m, err := s.matchService.GetMatch(ctx, j.MatchID)
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			return job.Permanent(err)
 		}
+		return err
+	}
+
+	if m.Status != match.Created || m.DownloadURL != "" || m.ShareCode == nil {
+		return nil
+	}
+
+	// Valve deletes matchmaking demos after a while.
+	if expired, err := s.matchService.ExpireMatch(ctx, m); expired || err != nil {
+		return err
+	}
+
+	sc := m.ShareCode
+	response := s.awaitLookup(sc.MatchID)
+	defer s.forgetLookup(sc.MatchID)
+
+	go s.RequestMatch(sc)
+
+	select {
+	case <-response:
+		const msg = "gamecoordinator: received response for %s"
+		log.Debugf(msg, sc.Encoded)
+		return nil
+	case <-time.After(requestTimeout):
+		const msg = "gamecoordinator: failed to receive response for %s"
+		return fmt.Errorf(msg, sc.Encoded)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Registers a lookup of the match and returns the channel that is closed once its response has been handled.
+func (s *Service) awaitLookup(matchID uint64) <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	response := make(chan struct{})
+	s.pending[matchID] = response
+	return response
+}
+
+// Removes the lookup of the match, e.g. because the request timed out.
+func (s *Service) forgetLookup(matchID uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.pending, matchID)
+}
+
+// Notifies the lookup of the match about its response. Nobody waits for the response if the request timed out.
+func (s *Service) resolveLookup(matchID uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-		<-t.C
+	if response, ok := s.pending[matchID]; ok {
+		close(response)
+		delete(s.pending, matchID)
 	}
 }
 
@@ -1,6 +1,8 @@
 package gamecoordinator
 
 import (
+	"context"
+
 	"github.com/Cludch/csgo-tools/pkg/share_code"
 	"github.com/Philipp15b/go-steam/v2"
 	"github.com/Philipp15b/go-steam/v2/protocol/gamecoordinator"
@@ -8,7 +10,7 @@ import (
 )
 
 type UseCase interface {
-	Connect(client *steam.Client)
+	Connect(ctx context.Context, client *steam.Client)
 	BuildHandlerMap()
 	ShakeHands()
 	SetPlaying(playing bool)
@@ -20,6 +22,6 @@ type UseCase interface {
 
 	HandleGCPacket(packet *gamecoordinator.GCPacket)
 	HandleMatchList(packet *gamecoordinator.GCPacket)
-	HandleGCReady(e *GCReadyEvent)
+	HandleGCReady(ctx context.Context, e *GCReadyEvent)
 	HandleClientWelcome(packet *gamecoordinator.GCPacket)
 }
@@ -1,16 +1,31 @@
 package gamecoordinator
 
 import (
+	"sync"
+
+	"github.com/Cludch/csgo-tools/internal/config"
+	"github.com/Cludch/csgo-tools/internal/domain/job"
 	"github.com/Cludch/csgo-tools/internal/domain/match"
 )
 
 type Service struct {
-	matchService match.UseCase
-	gc           *GC
+	configurationService config.UseCase
+	matchService         match.UseCase
+	jobService           job.UseCase
+	gc                   *GC
+
+	// pending holds the lookups waiting for their response by the Valve match id.
+	mu      sync.Mutex
+	pending map[uint64]chan struct{}
+	// sessions counts the GameCoordinator sessions, each of which runs the lookups as its own job owner.
+	sessions uint64
 }
 
-func NewService(m match.UseCase) *Service {
+func NewService(c config.UseCase, m match.UseCase, j job.UseCase) *Service {
 	return &Service{
-		matchService: m,
+		configurationService: c,
+		matchService:         m,
+		jobService:           j,
+		pending:              make(map[uint64]chan struct{}),
 	}
 }
@@ -1,5 +1,7 @@
 package steam_client
 
+import "context"
+
 type UseCase interface {
-	Connect(username, password, twoFactorSecret string)
+	Connect(ctx context.Context, username, password, twoFactorSecret string) error
 }
@@ -1,6 +1,11 @@
 package steam_client
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
 	"github.com/Cludch/csgo-tools/internal/gamecoordinator"
 	"github.com/Philipp15b/go-steam/v2"
 	"github.com/Philipp15b/go-steam/v2/protocol/steamlang"
@@ -19,7 +24,9 @@ func NewService(g gamecoordinator.UseCase) *Service {
 	}
 }
 
-func (s *Service) Connect(username, password, twoFactorSecret string) {
+// Connect signs in to Steam and connects to the GameCoordinator. It blocks until the connection is lost, which is
+// returned as error, or until the context is cancelled.
+func (s *Service) Connect(ctx context.Context, username, password, twoFactorSecret string) error {
 	totpInstance := totp.NewTotp(twoFactorSecret)
 
 	myLoginInfo := new(steam.LogOnDetails)
@@ -33,26 +40,42 @@ func (s *Service) Connect(username, password, twoFactorSecret string) {
 	myLoginInfo.TwoFactorCode = twoFactorCode
 
 	client := steam.NewClient()
-	if _, connectErr := client.Connect(); connectErr != nil {
-		log.Panic(connectErr)
+	if _, err := client.Connect(); err != nil {
+		return err
 	}
 
-	for event := range client.Events() {
-		switch e := event.(type) {
-		case *steam.ConnectedEvent:
-			log.Info("connected to steam. Logging in...")
-			client.Auth.LogOn(myLoginInfo)
-		case *steam.LoggedOnEvent:
-			log.Info("logged on")
-			client.Social.SetPersonaState(steamlang.EPersonaState_Invisible)
-
-			s.gamecoordinatorService.Connect(client)
-		case *gamecoordinator.GCReadyEvent:
-			s.gamecoordinatorService.HandleGCReady(e)
-		case steam.DisconnectedEvent:
-			log.Panic("steam_client: disconnected")
-		case steam.FatalErrorEvent:
-			log.Fatal(e)
+	// The GameCoordinator requests of this connection stop once it is closed.
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	connCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	for {
+		select {
+		case <-ctx.Done():
+			client.Disconnect()
+			return nil
+		case event := <-client.Events():
+			switch e := event.(type) {
+			case *steam.ConnectedEvent:
+				log.Info("connected to steam. Logging in...")
+				client.Auth.LogOn(myLoginInfo)
+			case *steam.LoggedOnEvent:
+				log.Info("logged on")
+				client.Social.SetPersonaState(steamlang.EPersonaState_Invisible)
+
+				s.gamecoordinatorService.Connect(connCtx, client)
+			case *gamecoordinator.GCReadyEvent:
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					s.gamecoordinatorService.HandleGCReady(connCtx, e)
+				}()
+			case *steam.DisconnectedEvent:
+				return errors.New("steam_client: disconnected")
+			case steam.FatalErrorEvent:
+				return fmt.Errorf("steam_client: %w", e)
+			}
 		}
 	}
 }
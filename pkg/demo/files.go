@@ -1,8 +1,7 @@
 package demo
 
 import (
-	"os"
-	"path/filepath"
+	"fmt"
 	"time"
 
 	"github.com/Cludch/csgo-tools/internal/domain/entity"
@@ -15,43 +14,8 @@ type Demo struct {
 	Filename  string
 }
 
-// ScanDemosDir scans the demos dir and returns all match ids.
-func ScanDemosDir(path string) ([]*Demo, error) {
-	var demos []*Demo
-
-	err := filepath.Walk(path,
-		func(path string, info os.FileInfo, err error) error {
-			if err != nil {
-				return err
-			}
-
-			if info.IsDir() {
-				return nil
-			}
-
-			// Ignore non .dem-files.
-			if filepath.Ext(path) != ".dem" {
-				return nil
-			}
-
-			filename := info.Name()
-
-			// Get file creation date.
-			modTime := time.Now()
-
-			stats, err := os.Stat(path)
-			if err == nil {
-				modTime = stats.ModTime()
-			}
-
-			// Add demo.
-			demos = append(demos, &Demo{ID: entity.NewID(), MatchTime: modTime, Filename: filename})
-
-			return nil
-		})
-	if err != nil {
-		return nil, nil
-	}
-
-	return demos, nil
+// ReplayFilename returns the name of the replay file of a round which is stored next to the demo.
+func ReplayFilename(demoFilename string, round byte) string {
+	const name = "%s_round%02d.replay.json.gz"
+	return fmt.Sprintf(name, TrimExtension(demoFilename), round)
 }
@@ -0,0 +1,74 @@
+package demo
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+)
+
+// Fingerprint identifies a demo by its content and by its header.
+type Fingerprint struct {
+	// Checksum is the hex encoded SHA-256 hash of the uncompressed demo.
+	Checksum      string `json:"checksum" bson:"checksum"`
+	ServerName    string `json:"serverName" bson:"serverName"`
+	MapName       string `json:"mapName" bson:"mapName"`
+	PlaybackTicks int    `json:"playbackTicks" bson:"playbackTicks"`
+}
+
+// NewFingerprint creates the fingerprint of a demo from its header and checksum.
+func NewFingerprint(header *Header, checksum string) *Fingerprint {
+	return &Fingerprint{
+		Checksum:      checksum,
+		ServerName:    header.ServerName,
+		MapName:       header.MapName,
+		PlaybackTicks: header.PlaybackTicks,
+	}
+}
+
+// ReadFingerprint reads the uncompressed demo from the reader and creates its fingerprint.
+func ReadFingerprint(r io.Reader) (*Fingerprint, error) {
+	hash := sha256.New()
+	var header bytes.Buffer
+
+	h, err := ReadHeader(io.TeeReader(r, &header))
+	if err != nil {
+		return nil, err
+	}
+
+	hash.Write(header.Bytes())
+	if _, err := io.Copy(hash, r); err != nil {
+		return nil, err
+	}
+
+	return NewFingerprint(h, hex.EncodeToString(hash.Sum(nil))), nil
+}
+
+// FingerprintOf creates the fingerprint of a demo in the store, which may be compressed.
+func FingerprintOf(ctx context.Context, s DemoStore, filename string) (*Fingerprint, error) {
+	r, err := OpenDemo(ctx, s, filename)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return ReadFingerprint(r)
+}
+
+// IsDuplicateOf returns whether both fingerprints belong to the same demo. Demos are the same if their content is
+// equal or if they have been recorded on the same server, map and have the same length, which is the case for
+// demos that have been re-encoded.
+func (f *Fingerprint) IsDuplicateOf(other *Fingerprint) bool {
+	if f == nil || other == nil {
+		return false
+	}
+
+	if f.Checksum != "" && f.Checksum == other.Checksum {
+		return true
+	}
+
+	return f.ServerName != "" && f.ServerName == other.ServerName &&
+		f.MapName == other.MapName &&
+		f.PlaybackTicks > 0 && f.PlaybackTicks == other.PlaybackTicks
+}
@@ -0,0 +1,34 @@
+package demo_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Cludch/csgo-tools/pkg/demo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadFingerprint(t *testing.T) {
+	content := append(newHeader("HL2DEMO", 153632), []byte("frames")...)
+
+	fingerprint, err := demo.ReadFingerprint(bytes.NewReader(content))
+
+	assert.Nil(t, err)
+	assert.Equal(t, "Valve CS:GO EU West Server", fingerprint.ServerName)
+	assert.Equal(t, "de_inferno", fingerprint.MapName)
+	assert.Equal(t, 153632, fingerprint.PlaybackTicks)
+	assert.Len(t, fingerprint.Checksum, 64)
+
+	other, _ := demo.ReadFingerprint(bytes.NewReader(append(newHeader("HL2DEMO", 153632), []byte("other")...)))
+	assert.NotEqual(t, fingerprint.Checksum, other.Checksum)
+}
+
+func TestIsDuplicateOf(t *testing.T) {
+	fingerprint := &demo.Fingerprint{Checksum: "a", ServerName: "Valve CS:GO EU West Server", MapName: "de_inferno", PlaybackTicks: 153632}
+
+	assert.True(t, fingerprint.IsDuplicateOf(&demo.Fingerprint{Checksum: "a"}))
+	assert.True(t, fingerprint.IsDuplicateOf(&demo.Fingerprint{Checksum: "b", ServerName: "Valve CS:GO EU West Server", MapName: "de_inferno", PlaybackTicks: 153632}))
+	assert.False(t, fingerprint.IsDuplicateOf(&demo.Fingerprint{Checksum: "b", ServerName: "Valve CS:GO EU West Server", MapName: "de_inferno", PlaybackTicks: 153633}))
+	assert.False(t, fingerprint.IsDuplicateOf(&demo.Fingerprint{}))
+	assert.False(t, fingerprint.IsDuplicateOf(nil))
+}
@@ -0,0 +1,89 @@
+package demo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"time"
+)
+
+// HeaderSize is the size of the header at the beginning of each uncompressed demo in bytes.
+const HeaderSize = 1072
+
+// Magic is the file stamp every demo starts with.
+const Magic = "HL2DEMO"
+
+// ErrInvalidHeader is returned if a file does not start with a valid demo header.
+var ErrInvalidHeader = errors.New("demo: invalid header")
+
+// Header holds the meta information at the beginning of a demo.
+type Header struct {
+	Protocol        int
+	NetworkProtocol int
+	ServerName      string
+	ClientName      string
+	MapName         string
+	GameDirectory   string
+	PlaybackTime    time.Duration
+	PlaybackTicks   int
+	PlaybackFrames  int
+	SignonLength    int
+}
+
+// Length of the strings in the header.
+const headerStringSize = 260
+
+// ReadHeader reads and validates the header of an uncompressed demo.
+// It returns ErrInvalidHeader if the magic is missing or the demo has no playback ticks, which happens if the demo
+// has not been finished by the server.
+func ReadHeader(r io.Reader) (*Header, error) {
+	buf := make([]byte, HeaderSize)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+			return nil, ErrInvalidHeader
+		}
+		return nil, err
+	}
+
+	if string(buf[:len(Magic)]) != Magic || buf[len(Magic)] != 0 {
+		return nil, ErrInvalidHeader
+	}
+
+	offset := 8
+	nextInt := func() int {
+		value := int32(binary.LittleEndian.Uint32(buf[offset:]))
+		offset += 4
+		return int(value)
+	}
+	nextString := func() string {
+		value := buf[offset : offset+headerStringSize]
+		offset += headerStringSize
+		if i := bytes.IndexByte(value, 0); i >= 0 {
+			value = value[:i]
+		}
+		return string(value)
+	}
+
+	h := &Header{}
+	h.Protocol = nextInt()
+	h.NetworkProtocol = nextInt()
+	h.ServerName = nextString()
+	h.ClientName = nextString()
+	h.MapName = nextString()
+	h.GameDirectory = nextString()
+
+	playbackTime := math.Float32frombits(binary.LittleEndian.Uint32(buf[offset:]))
+	offset += 4
+	h.PlaybackTime = time.Duration(float64(playbackTime) * float64(time.Second))
+	h.PlaybackTicks = nextInt()
+	h.PlaybackFrames = nextInt()
+	h.SignonLength = nextInt()
+
+	if h.PlaybackTicks <= 0 {
+		return h, ErrInvalidHeader
+	}
+
+	return h, nil
+}
@@ -0,0 +1,53 @@
+package demo_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/Cludch/csgo-tools/pkg/demo"
+	"github.com/stretchr/testify/assert"
+)
+
+func newHeader(magic string, ticks int32) []byte {
+	buf := make([]byte, demo.HeaderSize)
+	copy(buf, magic)
+	binary.LittleEndian.PutUint32(buf[8:], 4)
+	binary.LittleEndian.PutUint32(buf[12:], 13806)
+	copy(buf[16:], "Valve CS:GO EU West Server")
+	copy(buf[16+260*2:], "de_inferno")
+	copy(buf[16+260*3:], "csgo")
+	binary.LittleEndian.PutUint32(buf[1056:], math.Float32bits(2400.5))
+	binary.LittleEndian.PutUint32(buf[1060:], uint32(ticks))
+	binary.LittleEndian.PutUint32(buf[1064:], uint32(ticks/2))
+	return buf
+}
+
+func TestReadHeader(t *testing.T) {
+	header, err := demo.ReadHeader(bytes.NewReader(newHeader("HL2DEMO", 153632)))
+
+	assert.Nil(t, err)
+	assert.Equal(t, 4, header.Protocol)
+	assert.Equal(t, 13806, header.NetworkProtocol)
+	assert.Equal(t, "Valve CS:GO EU West Server", header.ServerName)
+	assert.Equal(t, "", header.ClientName)
+	assert.Equal(t, "de_inferno", header.MapName)
+	assert.Equal(t, "csgo", header.GameDirectory)
+	assert.Equal(t, 2400500*time.Millisecond, header.PlaybackTime)
+	assert.Equal(t, 153632, header.PlaybackTicks)
+	assert.Equal(t, 76816, header.PlaybackFrames)
+}
+
+func TestReadHeaderInvalid(t *testing.T) {
+	_, err := demo.ReadHeader(bytes.NewReader(newHeader("PK", 153632)))
+	assert.Equal(t, demo.ErrInvalidHeader, err)
+
+	// The demo has not been finished by the server.
+	_, err = demo.ReadHeader(bytes.NewReader(newHeader("HL2DEMO", 0)))
+	assert.Equal(t, demo.ErrInvalidHeader, err)
+
+	_, err = demo.ReadHeader(bytes.NewReader(content))
+	assert.Equal(t, demo.ErrInvalidHeader, err)
+}
@@ -0,0 +1,105 @@
+package demo
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Extensions of demo files which can be parsed, either uncompressed or compressed.
+const (
+	Extension     = ".dem"
+	ExtensionBz2  = ".dem.bz2"
+	ExtensionGzip = ".dem.gz"
+	ExtensionZstd = ".dem.zst"
+)
+
+var extensions = []string{Extension, ExtensionBz2, ExtensionGzip, ExtensionZstd}
+
+// IsDemoFile returns whether the file is an uncompressed or compressed demo file.
+func IsDemoFile(filename string) bool {
+	return demoExtension(filename) != ""
+}
+
+// TrimExtension returns the filename without the (compressed) demo extension.
+func TrimExtension(filename string) string {
+	return strings.TrimSuffix(filename, demoExtension(filename))
+}
+
+// Returns the (compressed) demo extension of the file or an empty string if it is no demo.
+func demoExtension(filename string) string {
+	for _, extension := range extensions {
+		if strings.HasSuffix(filename, extension) {
+			return extension
+		}
+	}
+
+	return ""
+}
+
+// Magic bytes of the compressed demo files.
+var (
+	magicBz2  = []byte("BZh")
+	magicGzip = []byte{0x1f, 0x8b}
+	magicZstd = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// NewReader returns a reader which decompresses r depending on the magic bytes at the beginning of the stream,
+// thus a demo is read correctly regardless of its filename. Uncompressed demos are returned as they are.
+// The demo itself is not validated, use ReadHeader on the returned reader for that.
+func NewReader(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	start, err := br.Peek(len(magicZstd))
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	switch {
+	case bytes.HasPrefix(start, magicBz2):
+		return io.NopCloser(bzip2.NewReader(br)), nil
+	case bytes.HasPrefix(start, magicGzip):
+		return gzip.NewReader(br)
+	case bytes.HasPrefix(start, magicZstd):
+		decoder, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+
+		return decoder.IOReadCloser(), nil
+	default:
+		return io.NopCloser(br), nil
+	}
+}
+
+// Open opens the demo file and transparently decompresses it.
+func Open(path string) (io.ReadCloser, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return &closeBoth{ReadCloser: r, inner: f}, nil
+}
+
+// Closes the decompressing reader and the underlying one.
+type closeBoth struct {
+	io.ReadCloser
+	inner io.Closer
+}
+
+func (r *closeBoth) Close() error {
+	r.ReadCloser.Close()
+	return r.inner.Close()
+}
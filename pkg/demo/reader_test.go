@@ -0,0 +1,77 @@
+package demo_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"io"
+	"testing"
+
+	"github.com/Cludch/csgo-tools/pkg/demo"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+)
+
+var content = []byte("HL2DEMO\x00")
+
+func TestIsDemoFile(t *testing.T) {
+	assert.True(t, demo.IsDemoFile("match.dem"))
+	assert.True(t, demo.IsDemoFile("match.dem.bz2"))
+	assert.True(t, demo.IsDemoFile("match.dem.gz"))
+	assert.True(t, demo.IsDemoFile("match.dem.zst"))
+	assert.False(t, demo.IsDemoFile("match.replay.json.gz"))
+	assert.False(t, demo.IsDemoFile("match.txt"))
+}
+
+func TestReplayFilename(t *testing.T) {
+	assert.Equal(t, "match_round03.replay.json.gz", demo.ReplayFilename("match.dem", 3))
+	assert.Equal(t, "match_round12.replay.json.gz", demo.ReplayFilename("match.dem.zst", 12))
+}
+
+func TestNewReaderGzip(t *testing.T) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, _ = w.Write(content)
+	w.Close()
+
+	assertDecompressed(t, &buf)
+}
+
+func TestNewReaderBz2(t *testing.T) {
+	// "HL2DEMO\x00" compressed with bzip2 -9.
+	compressed, _ := base64.StdEncoding.DecodeString("QlpoOTFBWSZTWR/JlwUAAAJMAEAAEAAGRqAAMQwIIDNJDkgni7kinChID+TLgoA=")
+
+	assertDecompressed(t, bytes.NewReader(compressed))
+}
+
+func TestNewReaderZstd(t *testing.T) {
+	var buf bytes.Buffer
+	w, _ := zstd.NewWriter(&buf)
+	_, _ = w.Write(content)
+	w.Close()
+
+	assertDecompressed(t, &buf)
+}
+
+func TestNewReaderUncompressed(t *testing.T) {
+	assertDecompressed(t, bytes.NewReader(content))
+}
+
+func TestNewReaderEmpty(t *testing.T) {
+	reader, err := demo.NewReader(bytes.NewReader(nil))
+	assert.Nil(t, err)
+
+	decompressed, err := io.ReadAll(reader)
+	assert.Nil(t, err)
+	assert.Empty(t, decompressed)
+}
+
+func assertDecompressed(t *testing.T, r io.Reader) {
+	reader, err := demo.NewReader(r)
+	assert.Nil(t, err)
+	defer reader.Close()
+
+	decompressed, err := io.ReadAll(reader)
+	assert.Nil(t, err)
+	assert.Equal(t, content, decompressed)
+}
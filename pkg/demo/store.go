@@ -0,0 +1,125 @@
+package demo
+
+import (
+	"context"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Cludch/csgo-tools/internal/domain/entity"
+	"github.com/klauspost/compress/zstd"
+)
+
+// FileInfo describes a file in a demo store.
+type FileInfo struct {
+	Filename string
+	Size     int64
+	ModTime  time.Time
+}
+
+// DemoStore stores demos and the files belonging to them like replays.
+// Stat returns an error wrapping os.ErrNotExist if the file does not exist.
+type DemoStore interface {
+	Save(ctx context.Context, filename string, r io.Reader, modTime time.Time) error
+	Open(ctx context.Context, filename string) (io.ReadCloser, error)
+	Stat(ctx context.Context, filename string) (*FileInfo, error)
+	Delete(ctx context.Context, filename string) error
+	List(context.Context) ([]*FileInfo, error)
+}
+
+// Exists returns whether the file exists in the store.
+func Exists(ctx context.Context, s DemoStore, filename string) bool {
+	_, err := s.Stat(ctx, filename)
+	return err == nil
+}
+
+// OpenDemo opens the demo from the store and transparently decompresses it.
+func OpenDemo(ctx context.Context, s DemoStore, filename string) (io.ReadCloser, error) {
+	f, err := s.Open(ctx, filename)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return &closeBoth{ReadCloser: r, inner: f}, nil
+}
+
+// SaveCompressed compresses the uncompressed demo from the reader using zstd and saves it in the store.
+// The filename of the compressed demo is returned.
+func SaveCompressed(ctx context.Context, s DemoStore, filename string, r io.Reader, modTime time.Time) (string, error) {
+	archiveFilename := TrimExtension(filename) + ExtensionZstd
+
+	pr, pw := io.Pipe()
+	go func() {
+		w, err := zstd.NewWriter(pw)
+		if err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+
+		if _, err := io.Copy(w, r); err != nil {
+			w.Close()
+			pw.CloseWithError(err)
+			return
+		}
+
+		pw.CloseWithError(w.Close())
+	}()
+
+	err := s.Save(ctx, archiveFilename, pr, modTime)
+	// Stop the compression if saving failed.
+	pr.CloseWithError(err)
+
+	return archiveFilename, err
+}
+
+// Archive compresses an uncompressed demo in the store using zstd and deletes the uncompressed file afterwards.
+// The modification time is kept and the filename of the archived demo is returned.
+// Demos that are already compressed are left untouched.
+func Archive(ctx context.Context, s DemoStore, filename string) (string, error) {
+	if !strings.HasSuffix(filename, Extension) {
+		return filename, nil
+	}
+
+	info, err := s.Stat(ctx, filename)
+	if err != nil {
+		return filename, err
+	}
+
+	f, err := s.Open(ctx, filename)
+	if err != nil {
+		return filename, err
+	}
+	defer f.Close()
+
+	archiveFilename, err := SaveCompressed(ctx, s, filename, f, info.ModTime)
+	if err != nil {
+		// Do not leave incomplete archives behind.
+		s.Delete(ctx, archiveFilename)
+		return filename, err
+	}
+
+	return archiveFilename, s.Delete(ctx, filename)
+}
+
+// Scan lists all demos of the store.
+func Scan(ctx context.Context, s DemoStore) ([]*Demo, error) {
+	files, err := s.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	demos := make([]*Demo, 0, len(files))
+	for _, file := range files {
+		if IsDemoFile(file.Filename) {
+			demos = append(demos, &Demo{ID: entity.NewID(), MatchTime: file.ModTime, Filename: file.Filename})
+		}
+	}
+
+	return demos, nil
+}
@@ -1,6 +1,7 @@
 package faceitapi
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -14,12 +15,12 @@ type MatchDetailResponse struct {
 }
 
 // GetPlayerMatchHistory returns the match history for a given player.
-func GetMatchDetails(faceitAPIKey string, matchId string) (*MatchDetailResponse, error) {
+func GetMatchDetails(ctx context.Context, faceitAPIKey string, matchId string) (*MatchDetailResponse, error) {
 	playerResponse := &MatchDetailResponse{}
 
 	// Request match details.
 	client := &http.Client{}
-	request, _ := http.NewRequest("GET", fmt.Sprintf("https://open.faceit.com/data/v4/matches/%s", matchId), nil)
+	request, _ := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("https://open.faceit.com/data/v4/matches/%s", matchId), nil)
 	request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", faceitAPIKey))
 	r, err := client.Do(request)
 	if err != nil {
@@ -1,6 +1,7 @@
 package faceitapi
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -35,7 +36,7 @@ func (e *FaceitApiConnectionIssues) Error() string {
 }
 
 // GetPlayerMatchHistory returns the match history for a given player.
-func GetPlayerMatchHistory(faceitAPIKey string, playerId uuid.UUID) (*PlayerMatchHistoryResponse, error) {
+func GetPlayerMatchHistory(ctx context.Context, faceitAPIKey string, playerId uuid.UUID) (*PlayerMatchHistoryResponse, error) {
 	u, err := url.Parse(fmt.Sprintf("https://open.faceit.com/data/v4/players/%s/history", playerId))
 	if err != nil {
 		return nil, errors.New("faceitapi: unable to parse url")
@@ -54,7 +55,7 @@ func GetPlayerMatchHistory(faceitAPIKey string, playerId uuid.UUID) (*PlayerMatc
 
 	// Request player match history.
 	client := &http.Client{}
-	request, _ := http.NewRequest("GET", u.String(), nil)
+	request, _ := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
 	request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", faceitAPIKey))
 	r, rErr := client.Do(request)
 	if rErr != nil {
@@ -0,0 +1,136 @@
+package radar
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+)
+
+// Radius of the area in pixels each point adds heat to.
+const radius = 20
+
+// Opacity of the hottest spot drawn on top of the background.
+const maxAlpha = 0.75
+
+// Point is a position in pixel coordinates of the rendered image.
+type Point struct {
+	X float64
+	Y float64
+}
+
+// RenderHeatmap draws the points as heatmap on top of the background and writes it as PNG.
+// If no background is given, the heatmap is drawn on a dark square of the given size.
+func RenderHeatmap(w io.Writer, background image.Image, size int, points []Point) error {
+	var bounds image.Rectangle
+	if background != nil {
+		bounds = background.Bounds()
+	} else {
+		bounds = image.Rect(0, 0, size, size)
+	}
+
+	img := image.NewRGBA(bounds)
+	if background != nil {
+		draw.Draw(img, bounds, background, bounds.Min, draw.Src)
+	} else {
+		draw.Draw(img, bounds, &image.Uniform{C: color.RGBA{R: 30, G: 30, B: 30, A: 255}}, image.Point{}, draw.Src)
+	}
+
+	heat, maxHeat := accumulate(bounds, points)
+	if maxHeat == 0 {
+		return png.Encode(w, img)
+	}
+
+	width := bounds.Dx()
+	for y := 0; y < bounds.Dy(); y++ {
+		for x := 0; x < width; x++ {
+			value := heat[y*width+x] / maxHeat
+			if value <= 0 {
+				continue
+			}
+
+			c := gradient(value)
+			c.A = uint8(255 * maxAlpha * math.Min(1, value*2))
+			px := bounds.Min.X + x
+			py := bounds.Min.Y + y
+			img.Set(px, py, blend(img.RGBAAt(px, py), c))
+		}
+	}
+
+	return png.Encode(w, img)
+}
+
+// Adds a gaussian kernel for every point and returns the heat per pixel with the maximum value.
+func accumulate(bounds image.Rectangle, points []Point) ([]float64, float64) {
+	width, height := bounds.Dx(), bounds.Dy()
+	heat := make([]float64, width*height)
+	maxHeat := 0.0
+	sigma := float64(radius) / 2
+
+	for _, p := range points {
+		cx := int(p.X) - bounds.Min.X
+		cy := int(p.Y) - bounds.Min.Y
+
+		for y := cy - radius; y <= cy+radius; y++ {
+			if y < 0 || y >= height {
+				continue
+			}
+
+			for x := cx - radius; x <= cx+radius; x++ {
+				if x < 0 || x >= width {
+					continue
+				}
+
+				dx, dy := float64(x-cx), float64(y-cy)
+				distance := dx*dx + dy*dy
+				if distance > radius*radius {
+					continue
+				}
+
+				index := y*width + x
+				heat[index] += math.Exp(-distance / (2 * sigma * sigma))
+				if heat[index] > maxHeat {
+					maxHeat = heat[index]
+				}
+			}
+		}
+	}
+
+	return heat, maxHeat
+}
+
+// Maps a value between 0 and 1 to a color from blue over green and yellow to red.
+func gradient(value float64) color.RGBA {
+	stops := []color.RGBA{
+		{R: 0, G: 0, B: 255},
+		{R: 0, G: 255, B: 0},
+		{R: 255, G: 255, B: 0},
+		{R: 255, G: 0, B: 0},
+	}
+
+	position := value * float64(len(stops)-1)
+	index := int(position)
+	if index >= len(stops)-1 {
+		return stops[len(stops)-1]
+	}
+
+	t := position - float64(index)
+	from, to := stops[index], stops[index+1]
+	return color.RGBA{
+		R: uint8(float64(from.R) + t*(float64(to.R)-float64(from.R))),
+		G: uint8(float64(from.G) + t*(float64(to.G)-float64(from.G))),
+		B: uint8(float64(from.B) + t*(float64(to.B)-float64(from.B))),
+	}
+}
+
+// Draws the color with its alpha value over the opaque background color.
+func blend(background color.RGBA, c color.RGBA) color.RGBA {
+	alpha := float64(c.A) / 255
+	mix := func(b, f uint8) uint8 {
+		return uint8(float64(b)*(1-alpha) + float64(f)*alpha)
+	}
+
+	return color.RGBA{R: mix(background.R, c.R), G: mix(background.G, c.G), B: mix(background.B, c.B), A: 255}
+}
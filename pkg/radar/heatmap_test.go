@@ -0,0 +1,25 @@
+package radar_test
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+
+	"github.com/Cludch/csgo-tools/pkg/radar"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderHeatmap(t *testing.T) {
+	var buf bytes.Buffer
+	err := radar.RenderHeatmap(&buf, nil, 64, []radar.Point{{X: 32, Y: 32}, {X: 32, Y: 33}, {X: 100, Y: 100}})
+	assert.Nil(t, err)
+
+	img, err := png.Decode(&buf)
+	assert.Nil(t, err)
+	assert.Equal(t, 64, img.Bounds().Dx())
+
+	// The center is hot while the corner keeps the background color.
+	r, _, _, _ := img.At(32, 32).RGBA()
+	cr, _, _, _ := img.At(0, 0).RGBA()
+	assert.Greater(t, r, cr)
+}
@@ -0,0 +1,107 @@
+package radar
+
+import (
+	"bufio"
+	"embed"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// RadarSize is the width and height of the radar images in pixels the overview coordinates relate to.
+const RadarSize = 1024
+
+//go:embed data/*.txt
+var data embed.FS
+
+// ErrUnknownMap is returned when there is no overview for a map.
+var ErrUnknownMap = errors.New("radar: unknown map")
+
+// Overview holds the information required to translate world coordinates to radar image coordinates.
+type Overview struct {
+	Map   string
+	PosX  float64
+	PosY  float64
+	Scale float64
+}
+
+var keyValue = regexp.MustCompile(`^\s*"([^"]+)"\s+"([^"]*)"`)
+
+// GetOverview returns the overview for a map from the shipped radar files.
+func GetOverview(mapName string) (*Overview, error) {
+	f, err := data.Open(fmt.Sprintf("data/%s.txt", mapName))
+	if err != nil {
+		return nil, ErrUnknownMap
+	}
+	defer f.Close()
+
+	return ParseOverview(mapName, f)
+}
+
+// ParseOverview reads an overview from a radar txt file as shipped with the game.
+func ParseOverview(mapName string, r io.Reader) (*Overview, error) {
+	o := &Overview{Map: mapName}
+	found := 0
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		matches := keyValue.FindStringSubmatch(scanner.Text())
+		if matches == nil {
+			continue
+		}
+
+		var target *float64
+		switch strings.ToLower(matches[1]) {
+		case "pos_x":
+			target = &o.PosX
+		case "pos_y":
+			target = &o.PosY
+		case "scale":
+			target = &o.Scale
+		default:
+			continue
+		}
+
+		value, err := strconv.ParseFloat(matches[2], 64)
+		if err != nil {
+			return nil, err
+		}
+
+		*target = value
+		found++
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if found != 3 || o.Scale == 0 {
+		const msg = "radar: incomplete overview for %s"
+		return nil, fmt.Errorf(msg, mapName)
+	}
+
+	return o, nil
+}
+
+// Translate converts world coordinates to pixel coordinates on the radar image.
+func (o *Overview) Translate(x float64, y float64) (float64, float64) {
+	return (x - o.PosX) / o.Scale, (o.PosY - y) / o.Scale
+}
+
+// LoadImage reads the radar image of a map (e.g. de_dust2_radar.png) from the given directory.
+func LoadImage(dir string, mapName string) (image.Image, error) {
+	f, err := os.Open(filepath.Join(dir, mapName+"_radar.png"))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return png.Decode(f)
+}
@@ -0,0 +1,32 @@
+package radar_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Cludch/csgo-tools/pkg/radar"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetOverview(t *testing.T) {
+	o, err := radar.GetOverview("de_dust2")
+	assert.Nil(t, err)
+	assert.Equal(t, float64(-2476), o.PosX)
+	assert.Equal(t, float64(3239), o.PosY)
+	assert.Equal(t, 4.4, o.Scale)
+
+	_, err = radar.GetOverview("de_unknown")
+	assert.Equal(t, radar.ErrUnknownMap, err)
+}
+
+func TestParseOverviewIncomplete(t *testing.T) {
+	_, err := radar.ParseOverview("de_test", strings.NewReader(`"de_test" { "pos_x" "1" }`))
+	assert.NotNil(t, err)
+}
+
+func TestTranslate(t *testing.T) {
+	o := &radar.Overview{PosX: -1000, PosY: 1000, Scale: 2}
+	x, y := o.Translate(0, 0)
+	assert.Equal(t, float64(500), x)
+	assert.Equal(t, float64(500), y)
+}
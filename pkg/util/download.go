@@ -1,18 +1,22 @@
 package util
 
 import (
-	"compress/bzip2"
-	"compress/gzip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"path"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"time"
 
+	"github.com/Cludch/csgo-tools/pkg/demo"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -34,67 +38,172 @@ func IsDemoNotFoundError(err error) bool {
 	return ok
 }
 
-// DownloadDemo will download a demo from an url and decompress and store it in local filepath.
-// It writes to the destination file as it downloads it, without
-// loading the entire file into memory.
-func DownloadDemo(url string, demoDir string, lastModified time.Time) error {
+// Download describes a demo that has been downloaded and verified.
+type Download struct {
+	// Filename is the filename of the demo in the store.
+	Filename string
+	// Size is the size of the downloaded file in bytes.
+	Size int64
+	// Duration is the time it took to download, verify and save the demo.
+	Duration time.Duration
+	// Fingerprint identifies the demo by its checksum and header.
+	Fingerprint *demo.Fingerprint
+}
+
+// DownloadDemo will download a demo from an url, decompress it and save it in the store.
+// The download is written to a partial file in tempDir first, which is resumed if the download gets interrupted.
+// Once completed, the demo is decompressed and its header is verified while it gets saved.
+// If compress is set, the demo gets compressed using zstd before saving it.
+// Cancelling the context stops the download, which is resumed by the next call.
+func DownloadDemo(ctx context.Context, url string, store demo.DemoStore, tempDir string, lastModified time.Time, compress bool) (*Download, error) {
 	// Validate the url
 	reValve := regexp.MustCompile(`^http:\/\/replay[\d]{3}\.valve\.net\/730\/[\d]{21}_([\d]*)\.dem\.bz2$`)
 	reFaceit := regexp.MustCompile(`^https:\/\/demos-([\w]*)-([\w]*)\.faceit-cdn\.net\/csgo\/[\d]{1}-\b[0-9a-f]{8}\b-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-\b[0-9a-f]{12}\b-[\d]{1}-[\d]{1}\.dem\.gz$`)
 
 	if !reValve.MatchString(url) && !reFaceit.MatchString(url) {
-		return ErrInvalidDownloadURL
+		return nil, ErrInvalidDownloadURL
+	}
+
+	start := time.Now()
+	partialPath := filepath.Join(tempDir, path.Base(url)+".part")
+
+	size, err := DownloadFile(ctx, url, partialPath)
+	if err != nil {
+		return nil, err
 	}
 
 	// Get file name.
-	filename := strings.Split(path.Base(url), ".")[0] + ".dem"
-	filePath := path.Join(demoDir, filename)
+	filename := strings.Split(path.Base(url), ".")[0] + demo.Extension
 
-	// Get the data.
-	resp, err := http.Get(url) //nolint // We have to take dynamic replay urls in order to download them. URL is validated before.
-	if err != nil || resp.StatusCode != http.StatusOK {
-		resp.Body.Close()
-		return errDemoNotFound{URL: url}
+	f, err := os.Open(partialPath)
+	if err != nil {
+		return nil, err
 	}
+	defer f.Close()
 
-	defer resp.Body.Close()
+	// Decompress, verify and save. The partial file is removed if it turns out to be broken, thus it is downloaded again.
+	cr, err := demo.NewReader(f)
+	if err != nil {
+		os.Remove(partialPath)
+		return nil, err
+	}
+	defer cr.Close()
 
-	// Create the file.
-	out, err := os.Create(filePath)
+	var header bytes.Buffer
+	h, err := demo.ReadHeader(io.TeeReader(cr, &header))
 	if err != nil {
-		out.Close()
-		log.Error(err)
-		return err
+		os.Remove(partialPath)
+		return nil, fmt.Errorf("%w: %s", err, url)
 	}
-	defer out.Close()
 
-	var cr io.Reader
-	if strings.HasSuffix(url, "gz") {
-		reader, err := gzip.NewReader(resp.Body)
-		if err != nil {
-			return err
-		}
-		cr = reader
-	} else if strings.HasSuffix(url, "bz2") {
-		cr = bzip2.NewReader(resp.Body)
+	hash := sha256.New()
+	r := &errorReader{r: io.TeeReader(io.MultiReader(&header, cr), hash)}
+
+	if compress {
+		filename, err = demo.SaveCompressed(ctx, store, filename, r, lastModified)
 	} else {
-		return errors.New("unknown file compression")
+		err = store.Save(ctx, filename, r, lastModified)
+	}
+
+	if err != nil {
+		if r.err != nil {
+			os.Remove(partialPath)
+		}
+		return nil, err
+	}
+
+	if err := os.Remove(partialPath); err != nil {
+		const msg = "unable to remove the partial download %s: %s"
+		log.Warnf(msg, partialPath, err)
+	}
+
+	return &Download{
+		Filename:    filename,
+		Size:        size,
+		Duration:    time.Since(start),
+		Fingerprint: demo.NewFingerprint(h, hex.EncodeToString(hash.Sum(nil))),
+	}, nil
+}
+
+// Remembers the last error while reading, which tells decompression errors apart from errors of the store.
+type errorReader struct {
+	r   io.Reader
+	err error
+}
+
+func (r *errorReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if err != nil && err != io.EOF {
+		r.err = err
+	}
+
+	return n, err
+}
+
+// DownloadFile downloads the url to the file at path and returns the size of the completed file.
+// If the file already exists, the download is resumed using a range request. If the download gets interrupted,
+// the partial file is kept in order to resume it later, which is also the case if the context gets cancelled.
+func DownloadFile(ctx context.Context, url string, path string) (int64, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	offset, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, err
 	}
 
-	// Decompress and write to file.
-	if _, err = io.Copy(out, cr); err != nil {
-		return err
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
 	}
 
-	// Close file before trying to update the modified information.
-	out.Close()
+	// Get the data.
+	resp, err := http.DefaultClient.Do(req) //nolint // We have to take dynamic replay urls in order to download them. URL is validated before.
+	if err != nil {
+		return 0, err
+	}
+
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && strings.HasPrefix(resp.Header.Get("Content-Range"), fmt.Sprintf("bytes %d-", offset)):
+		const msg = "resuming download of %s at %d bytes"
+		log.Debugf(msg, url, offset)
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		// The partial file is complete already.
+		return offset, nil
+	case resp.StatusCode == http.StatusOK:
+		// The server does not support resuming, thus the download starts over.
+		if err := f.Truncate(0); err != nil {
+			return 0, err
+		}
+		if offset, err = f.Seek(0, io.SeekStart); err != nil {
+			return 0, err
+		}
+	case resp.StatusCode == http.StatusPartialContent:
+		f.Truncate(0)
+		return 0, fmt.Errorf("unexpected content range %q: %s", resp.Header.Get("Content-Range"), url)
+	case resp.StatusCode >= http.StatusInternalServerError:
+		return 0, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, url)
+	default:
+		return 0, errDemoNotFound{URL: url}
+	}
+
+	n, err := io.Copy(f, resp.Body)
+	if err != nil {
+		return 0, err
+	}
 
-	// Update file modified information.
-	if err = os.Chtimes(filePath, lastModified, lastModified); err != nil {
-		const msg = "unable to set correct last modified date for demo %v"
-		log.Warnf(msg, filename)
-		log.Error(err)
+	if resp.ContentLength >= 0 && n != resp.ContentLength {
+		return 0, fmt.Errorf("%w: downloaded %d of %d bytes of %s", io.ErrUnexpectedEOF, n, resp.ContentLength, url)
 	}
 
-	return nil
+	return offset + n, f.Close()
 }
@@ -0,0 +1,91 @@
+package util_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Cludch/csgo-tools/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+var file = bytes.Repeat([]byte("HL2DEMO\x00"), 512)
+
+func newServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/match.dem.bz2" {
+			http.NotFound(w, r)
+			return
+		}
+
+		http.ServeContent(w, r, "match.dem.bz2", time.Time{}, bytes.NewReader(file))
+	}))
+}
+
+func TestDownloadFile(t *testing.T) {
+	server := newServer()
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "match.dem.bz2.part")
+	size, err := util.DownloadFile(context.Background(), server.URL+"/match.dem.bz2", path)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(len(file)), size)
+
+	downloaded, _ := os.ReadFile(path)
+	assert.Equal(t, file, downloaded)
+}
+
+func TestDownloadFileResume(t *testing.T) {
+	server := newServer()
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "match.dem.bz2.part")
+	assert.Nil(t, os.WriteFile(path, file[:1000], 0644))
+
+	size, err := util.DownloadFile(context.Background(), server.URL+"/match.dem.bz2", path)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(len(file)), size)
+
+	downloaded, _ := os.ReadFile(path)
+	assert.Equal(t, file, downloaded)
+
+	// The partial file is complete already.
+	size, err = util.DownloadFile(context.Background(), server.URL+"/match.dem.bz2", path)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(len(file)), size)
+}
+
+func TestDownloadFileNotFound(t *testing.T) {
+	server := newServer()
+	defer server.Close()
+
+	_, err := util.DownloadFile(context.Background(), server.URL+"/missing.dem.bz2", filepath.Join(t.TempDir(), "missing.dem.bz2.part"))
+	assert.True(t, util.IsDemoNotFoundError(err))
+}
+
+func TestDownloadFileCancelled(t *testing.T) {
+	server := newServer()
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "match.dem.bz2.part")
+	assert.Nil(t, os.WriteFile(path, file[:1000], 0644))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := util.DownloadFile(ctx, server.URL+"/match.dem.bz2", path)
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.False(t, util.IsDemoNotFoundError(err))
+
+	// The partial file is kept in order to resume the download.
+	downloaded, _ := os.ReadFile(path)
+	assert.Equal(t, file[:1000], downloaded)
+}
@@ -0,0 +1,10 @@
+package util
+
+// Rate returns the share of value in total or zero if there is nothing to divide.
+func Rate(value int, total int) float32 {
+	if total == 0 {
+		return 0
+	}
+
+	return float32(value) / float32(total)
+}
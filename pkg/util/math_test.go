@@ -0,0 +1,13 @@
+package util_test
+
+import (
+	"testing"
+
+	"github.com/Cludch/csgo-tools/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRate(t *testing.T) {
+	assert.Equal(t, float32(0.25), util.Rate(1, 4))
+	assert.Equal(t, float32(0), util.Rate(3, 0))
+}
@@ -0,0 +1,76 @@
+package util
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HostLimiter limits the rate of requests per host. Hosts are grouped by their domain, thus all replay servers of
+// Valve (replay*.valve.net) share one limit.
+type HostLimiter struct {
+	mu        sync.Mutex
+	intervals map[string]time.Duration
+	fallback  time.Duration
+	next      map[string]time.Time
+}
+
+// NewHostLimiter creates a limiter using the minimum interval between two requests per domain.
+// Domains missing in intervals use the fallback interval.
+func NewHostLimiter(intervals map[string]time.Duration, fallback time.Duration) *HostLimiter {
+	return &HostLimiter{
+		intervals: intervals,
+		fallback:  fallback,
+		next:      make(map[string]time.Time),
+	}
+}
+
+// Wait blocks until a request to the host of the url is allowed. Returns the error of the context if it gets cancelled
+// while waiting.
+func (l *HostLimiter) Wait(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+
+	t := time.NewTimer(l.reserve(Domain(u.Hostname()), time.Now()))
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// Reserves the next free slot of the domain and returns the time to wait for it.
+func (l *HostLimiter) reserve(domain string, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	interval, ok := l.intervals[domain]
+	if !ok {
+		interval = l.fallback
+	}
+
+	slot := l.next[domain]
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next[domain] = slot.Add(interval)
+
+	return slot.Sub(now)
+}
+
+// Domain returns the last two labels of the host, e.g. valve.net for replay123.valve.net.
+func Domain(host string) string {
+	labels := strings.Split(host, ".")
+	if len(labels) <= 2 {
+		return host
+	}
+
+	return strings.Join(labels[len(labels)-2:], ".")
+}
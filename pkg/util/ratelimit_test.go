@@ -0,0 +1,45 @@
+package util_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Cludch/csgo-tools/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDomain(t *testing.T) {
+	assert.Equal(t, "valve.net", util.Domain("replay183.valve.net"))
+	assert.Equal(t, "faceit-cdn.net", util.Domain("demos-europe-west2.faceit-cdn.net"))
+	assert.Equal(t, "localhost", util.Domain("localhost"))
+}
+
+func TestHostLimiter(t *testing.T) {
+	limiter := util.NewHostLimiter(map[string]time.Duration{"valve.net": 50 * time.Millisecond}, 0)
+	ctx := context.Background()
+
+	start := time.Now()
+	assert.Nil(t, limiter.Wait(ctx, "http://replay183.valve.net/730/003511041838392901859_0862131453.dem.bz2"))
+	assert.Nil(t, limiter.Wait(ctx, "http://replay134.valve.net/730/003511041838392901860_0862131454.dem.bz2"))
+	assert.GreaterOrEqual(t, int64(time.Since(start)), int64(50*time.Millisecond))
+
+	// Other domains are not limited by the requests to valve.
+	start = time.Now()
+	assert.Nil(t, limiter.Wait(ctx, "https://demos-europe-west2.faceit-cdn.net/csgo/match.dem.gz"))
+	assert.Nil(t, limiter.Wait(ctx, "https://demos-europe-west2.faceit-cdn.net/csgo/match.dem.gz"))
+	assert.Less(t, int64(time.Since(start)), int64(50*time.Millisecond))
+}
+
+func TestHostLimiterCancel(t *testing.T) {
+	limiter := util.NewHostLimiter(map[string]time.Duration{"valve.net": time.Hour}, 0)
+	url := "http://replay183.valve.net/730/003511041838392901859_0862131453.dem.bz2"
+	assert.Nil(t, limiter.Wait(context.Background(), url))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	assert.Equal(t, context.DeadlineExceeded, limiter.Wait(ctx, url))
+	assert.Less(t, int64(time.Since(start)), int64(time.Second))
+}
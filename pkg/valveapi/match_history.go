@@ -1,6 +1,7 @@
 package valveapi
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -28,7 +29,7 @@ func (e *InvalidMatchHistoryCredentials) Error() string {
 
 // GetNextMatch returns the next match's share code.
 // It uses the saved share codes as the current one.
-func GetNextMatch(steamAPIKey string, steamID uint64, historyAuthenticationCode string, lastShareCode string) (string, error) {
+func GetNextMatch(ctx context.Context, steamAPIKey string, steamID uint64, historyAuthenticationCode string, lastShareCode string) (string, error) {
 	// Get latest match
 	u, err := url.Parse("https://api.steampowered.com/ICSGOPlayers_730/GetNextMatchSharingCode/v1")
 	if err != nil {
@@ -48,7 +49,12 @@ func GetNextMatch(steamAPIKey string, steamID uint64, historyAuthenticationCode
 	matchResponse := &MatchResponse{}
 
 	// Request match code.
-	r, err := http.Get(u.String())
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+
+	r, err := http.DefaultClient.Do(request)
 	if err != nil {
 		return "", err
 	}